@@ -21,6 +21,11 @@ func main() {
 		case "update":
 			command.RunUpdate()
 			return
+		case "du":
+			navigation.CleanupNavFile()
+			ui.InitColors()
+			command.RunDu(os.Args[2:])
+			return
 		}
 	}
 
@@ -6,6 +6,7 @@ require (
 	github.com/fatih/color v1.18.0
 	github.com/mattn/go-isatty v0.0.20
 	golang.org/x/sys v0.25.0
+	golang.org/x/text v0.18.0
 )
 
 require github.com/mattn/go-colorable v0.1.13 // indirect
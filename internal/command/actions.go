@@ -0,0 +1,142 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ReggieAlbiosA/fcf/internal/fileops"
+	"github.com/ReggieAlbiosA/fcf/internal/store"
+	"github.com/ReggieAlbiosA/fcf/internal/ui"
+)
+
+// runFileAction previews a bulk copy, move or delete, asks for confirmation
+// and carries it out. Returns false if any file operation failed
+// When deleting --empty or --broken-links results, directories left empty
+// are removed too, up to but not including searchRoot
+func runFileAction(action fileops.Action, results *store.Paths, destDir, policy, searchRoot string) bool {
+	if action != fileops.Delete {
+		destDir = expandPath(destDir)
+		if abs, err := filepath.Abs(destDir); err == nil {
+			destDir = abs
+		}
+		if info, err := os.Stat(destDir); err == nil && !info.IsDir() {
+			fmt.Printf("%s '%s' is not a directory\n", ui.Colors.Error("ERROR:"), destDir)
+			return false
+		}
+	}
+
+	// The dry-run preview is always shown first
+	ops := fileops.Plan(action, results, destDir, policy)
+	fileops.ShowPlan(action, ops)
+
+	prune := action == fileops.Delete && (ui.Opts.Empty || ui.Opts.BrokenLinks)
+	if abs, err := filepath.Abs(searchRoot); err == nil {
+		searchRoot = abs
+	}
+	if prune {
+		fmt.Println(ui.Colors.Dim(fmt.Sprintf("Directories left empty are removed too, up to %s", searchRoot)))
+	}
+
+	if ui.Opts.DryRun {
+		fmt.Println(ui.Colors.Dim("(dry run: nothing was changed)"))
+		return true
+	}
+
+	pending := fileops.Pending(ops)
+	if pending == 0 {
+		fmt.Println(ui.Colors.Yellow("Nothing to do."))
+		return true
+	}
+
+	fmt.Println()
+	question := fmt.Sprintf("Are you sure you want to %s %d item(s)?", action, pending)
+	if action != fileops.Delete {
+		question = fmt.Sprintf("Are you sure you want to %s %d item(s) to %s?", action, pending, destDir)
+	}
+	response := strings.ToLower(readLine(ui.Colors.Prompt(question + " [y/N] ")))
+	if response != "y" && response != "yes" {
+		fmt.Println()
+		fmt.Println(ui.Colors.Yellow(fmt.Sprintf("%s cancelled.", action.Title())))
+		return true
+	}
+
+	if action != fileops.Delete {
+		if err := os.MkdirAll(destDir, 0755); err != nil {
+			fmt.Printf("%s Could not create '%s': %v\n", ui.Colors.Error("ERROR:"), destDir, err)
+			return false
+		}
+	}
+
+	outcome := fileops.Execute(action, ops)
+	if prune {
+		pruned := fileops.PruneEmptyParents(outcome, searchRoot)
+		if len(pruned) > 0 {
+			fmt.Println()
+		}
+		for _, r := range pruned {
+			if r.Err == nil {
+				fmt.Printf("  %s %s%c\n", ui.Colors.Dim("removed empty"), r.Op.Source, filepath.Separator)
+			}
+		}
+		outcome = append(outcome, pruned...)
+	}
+	fileops.ShowResults(action, outcome)
+	return !fileops.Failed(outcome)
+}
+
+// fileAction returns the action requested by --copy-to, --move-to or --delete
+func fileAction() (fileops.Action, string, bool) {
+	switch {
+	case ui.Opts.CopyTo != "":
+		return fileops.Copy, ui.Opts.CopyTo, true
+	case ui.Opts.MoveTo != "":
+		return fileops.Move, ui.Opts.MoveTo, true
+	case ui.Opts.Delete:
+		return fileops.Delete, "", true
+	}
+	return 0, "", false
+}
+
+// promptFileAction shows the interactive action menu for the results
+func promptFileAction(results *store.Paths, searchRoot string) {
+	fmt.Println()
+	fmt.Println(ui.Colors.Bold("Actions:"))
+	fmt.Printf("  %s Copy results to a directory\n", ui.Colors.Cyan("[c]"))
+	fmt.Printf("  %s Move results to a directory\n", ui.Colors.Cyan("[m]"))
+	fmt.Printf("  %s Delete results\n", ui.Colors.Cyan("[d]"))
+	fmt.Println()
+
+	var action fileops.Action
+	switch strings.ToLower(readLine(ui.Colors.Prompt("Choose: "))) {
+	case "c":
+		action = fileops.Copy
+	case "m":
+		action = fileops.Move
+	case "d":
+		action = fileops.Delete
+	default:
+		fmt.Println(ui.Colors.Dim("Cancelled"))
+		return
+	}
+
+	destDir := ""
+	policy := fileops.ConflictSkip
+	if action != fileops.Delete {
+		destDir = readLine(ui.Colors.Prompt("Destination directory: "))
+		if destDir == "" {
+			fmt.Println(ui.Colors.Dim("Cancelled"))
+			return
+		}
+
+		switch strings.ToLower(readLine(ui.Colors.Prompt("If a target exists: [s]kip, [o]verwrite or [r]ename? [s] "))) {
+		case "o":
+			policy = fileops.ConflictOverwrite
+		case "r":
+			policy = fileops.ConflictRename
+		}
+	}
+
+	runFileAction(action, results, destDir, policy, searchRoot)
+}
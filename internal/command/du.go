@@ -0,0 +1,155 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ReggieAlbiosA/fcf/internal/config"
+	"github.com/ReggieAlbiosA/fcf/internal/input"
+	"github.com/ReggieAlbiosA/fcf/internal/navigation"
+	"github.com/ReggieAlbiosA/fcf/internal/store"
+	"github.com/ReggieAlbiosA/fcf/internal/ui"
+	"github.com/ReggieAlbiosA/fcf/internal/usage"
+)
+
+// barWidth is the number of cells in a full usage bar
+const barWidth = 20
+
+// RunDu is called from main for the du command
+func RunDu(args []string) {
+	flags := flag.NewFlagSet("du", flag.ExitOnError)
+	top := flags.Int("top", 10, "Number of largest directories and files to show")
+	flags.BoolVar(&ui.Opts.ShowErrors, "show-errors", false, "List paths that could not be read")
+	flags.StringVar(&ui.Opts.Color, "color", config.Load().String("color", "auto"), "When to use colors: auto, always or never")
+	flags.Parse(args)
+
+	initColors()
+
+	path := "."
+	if flags.NArg() > 0 {
+		path = flags.Arg(0)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+
+	ui.ShowHeader()
+
+	info, err := os.Stat(absPath)
+	if err == nil && !info.IsDir() {
+		err = fmt.Errorf("%s is not a directory", absPath)
+	}
+	if err != nil {
+		fmt.Printf("%s %v\n", ui.Colors.Error("ERROR:"), err)
+		os.Exit(exitError)
+	}
+
+	fmt.Println(ui.Colors.Bold("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Printf("%s %s\n", ui.Colors.Blue("Disk usage of:"), ui.Colors.Cyan(absPath))
+	fmt.Println(ui.Colors.Bold("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	fmt.Printf("%s\n\n", ui.Colors.Yellow("[press 's' to stop]"))
+
+	// Stop on 's', like a search
+	stopChan := make(chan struct{})
+	var stopOnce sync.Once
+	keyChan := make(chan string, 10)
+	stopListener := input.StartKeyListener(keyChan)
+	go func() {
+		for key := range keyChan {
+			if strings.ToLower(key) == "s" {
+				stopOnce.Do(func() { close(stopChan) })
+				return
+			}
+		}
+	}()
+
+	startTime := time.Now()
+	progress := ui.StartProgress(false)
+	report := usage.Scan(absPath, usage.Options{
+		Top:    *top,
+		Stop:   stopChan,
+		OnDir:  progress.AddDir,
+		OnFile: progress.AddEntry,
+	})
+	progress.Stop()
+	stopListener()
+	elapsed := time.Since(startTime).Seconds()
+
+	paths := showUsage(report, elapsed)
+
+	errs := make([]ui.PathError, len(report.Errors))
+	for i, e := range report.Errors {
+		errs[i] = ui.PathError{Path: e.Path, Kind: ui.ClassifyError(e.Err), Dir: e.Dir, Err: e.Err}
+	}
+	ui.ShowErrors(errs, ui.Opts.ShowErrors)
+
+	if len(paths) > 0 {
+		targetPath := SelectResult(store.FromSlice(paths))
+		if targetPath != "" {
+			fmt.Println()
+			navigation.NavigateToPath(targetPath)
+		}
+	}
+}
+
+// showUsage prints the largest directories and files as numbered bars and
+// returns their paths in the same order, for SelectResult
+func showUsage(report *usage.Report, elapsed float64) []string {
+	var paths []string
+
+	if len(report.TopDirs) > 0 {
+		fmt.Println(ui.Colors.Bold("Largest directories:"))
+		for _, e := range report.TopDirs {
+			paths = append(paths, e.Path)
+			showUsageEntry(len(paths), e, report.Total)
+		}
+		fmt.Println()
+	}
+
+	if len(report.TopFiles) > 0 {
+		fmt.Println(ui.Colors.Bold("Largest files:"))
+		for _, e := range report.TopFiles {
+			paths = append(paths, e.Path)
+			showUsageEntry(len(paths), e, report.Total)
+		}
+		fmt.Println()
+	}
+
+	fmt.Println(ui.Colors.Bold("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	if report.Stopped {
+		fmt.Println(ui.Colors.Yellow("Scan stopped by user."))
+		fmt.Println(ui.Colors.Dim("(Sizes are partial)"))
+	}
+	fmt.Println(ui.Colors.Summary(fmt.Sprintf("Total: %s in %d files and %d directories (%.2fs)",
+		ui.FormatSize(report.Total), report.Files, report.Dirs, elapsed)))
+	fmt.Println(ui.Colors.Bold("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	return paths
+}
+
+// showUsageEntry prints one numbered line: size, share of the total as a bar, path
+func showUsageEntry(count int, e usage.Entry, total int64) {
+	share := 0.0
+	if total > 0 {
+		share = float64(e.Size) / float64(total)
+	}
+	filled := int(share*barWidth + 0.5)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+
+	path := ui.DisplayPath(e.Path)
+	if e.IsDir {
+		path = ui.Colors.Directory(fmt.Sprintf("%s%c", path, filepath.Separator))
+	}
+
+	fmt.Printf("%s %7s %s %5.1f%%  %s\n",
+		ui.Colors.Cyan(fmt.Sprintf("  [%d]", count)),
+		ui.FormatSize(e.Size),
+		ui.Colors.Cyan(bar),
+		share*100,
+		path)
+}
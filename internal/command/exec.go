@@ -0,0 +1,58 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ReggieAlbiosA/fcf/internal/runner"
+	"github.com/ReggieAlbiosA/fcf/internal/store"
+	"github.com/ReggieAlbiosA/fcf/internal/ui"
+)
+
+// runCommand runs a --exec (once per result) or --exec-batch (all results
+// at once) template on the results and reports each command's exit status
+func runCommand(template string, batch bool, results *store.Paths) []runner.Status {
+	cmd, err := runner.Parse(template)
+	if err != nil {
+		fmt.Printf("%s Invalid command: %v\n", ui.Colors.Error("ERROR:"), err)
+		return []runner.Status{{ExitCode: -1, Err: err}}
+	}
+
+	fmt.Println()
+	mode := "once per result"
+	if batch {
+		mode = "as a batch"
+	}
+	fmt.Printf("%s %s %s\n",
+		ui.Colors.Blue("Running:"),
+		ui.Colors.Cyan(cmd.String()),
+		ui.Colors.Dim(fmt.Sprintf("(%s, %d result(s))", mode, results.Len())))
+	fmt.Println()
+
+	var statuses []runner.Status
+	if batch {
+		statuses = runner.RunBatch(cmd, results)
+	} else {
+		statuses = runner.RunEach(cmd, results, ui.Opts.Jobs)
+	}
+
+	runner.ShowReport(statuses)
+	return statuses
+}
+
+// promptCommand asks for a command to run on the results (interactive mode)
+func promptCommand(results *store.Paths) {
+	fmt.Println()
+	fmt.Printf("%s Run a command on %d result(s)\n", ui.Colors.Bold("Command:"), results.Len())
+	fmt.Printf("%s\n", ui.Colors.Dim("Placeholders: {} path, {/} name, {//} parent, {.} path without ext, {/.} name without ext"))
+	fmt.Println()
+
+	template := readLine(ui.Colors.Prompt("Command: "))
+	if template == "" {
+		fmt.Println(ui.Colors.Dim("Cancelled"))
+		return
+	}
+
+	mode := readLine(ui.Colors.Prompt("Run [e]ach result separately or as one [b]atch? [e/b] "))
+	runCommand(template, strings.HasPrefix(strings.ToLower(mode), "b"), results)
+}
@@ -3,7 +3,9 @@ package command
 import (
 	"fmt"
 	"runtime"
+	"strings"
 
+	"github.com/ReggieAlbiosA/fcf/internal/config"
 	"github.com/ReggieAlbiosA/fcf/internal/platform"
 	"github.com/ReggieAlbiosA/fcf/internal/ui"
 )
@@ -26,15 +28,71 @@ func ShowHelp() {
 	fmt.Println(ui.Colors.Bold("OPTIONS:"))
 	fmt.Printf("    %s               Show this help message\n", ui.Colors.Cyan("-h, --help"))
 	fmt.Printf("    %s                  Case-insensitive pattern matching\n", ui.Colors.Cyan("-i"))
+	fmt.Printf("    %s   Case-sensitive pattern matching (overrides smart case)\n", ui.Colors.Cyan("-s, --case-sensitive"))
+	fmt.Printf("    %s        Ignore case unless the pattern has uppercase letters\n", ui.Colors.Cyan("--smart-case"))
 	fmt.Printf("    %s           Filter by type: %s(file) or %s(directory)\n",
 		ui.Colors.Cyan("-t TYPE"), ui.Colors.Yellow("f"), ui.Colors.Yellow("d"))
 	fmt.Printf("    %s           Display file sizes\n", ui.Colors.Cyan("--show-size"))
-	fmt.Printf("    %s    Maximum results to display (default: unlimited)\n", ui.Colors.Cyan("--max-display NUM"))
+	fmt.Printf("    %s           Display recursive directory sizes\n", ui.Colors.Cyan("--dir-sizes"))
+	fmt.Printf("    %s    Maximum results to display (default: unlimited; not for JSON)\n", ui.Colors.Cyan("--max-display NUM"))
+	fmt.Printf("    %s    Stop searching after NUM matches\n", ui.Colors.Cyan("--max-results NUM"))
+	fmt.Printf("    %s          Stop searching after the first match\n", ui.Colors.Cyan("-1, --first"))
+	fmt.Printf("    %s                Search level by level, shallow matches first\n", ui.Colors.Cyan("--bfs"))
+	fmt.Printf("    %s             Same result order on every run (sorted depth-first)\n", ui.Colors.Cyan("--stable"))
+	fmt.Printf("    %s           Show permissions, links, owner, group, size and date\n", ui.Colors.Cyan("-l, --long"))
+	fmt.Printf("    %s     Paths relative to the search root, or %s to the current directory\n",
+		ui.Colors.Cyan("--relative[=cwd]"), ui.Colors.Yellow("=cwd"))
+	fmt.Printf("    %s          Make results clickable links to their files (OSC 8)\n", ui.Colors.Cyan("--hyperlink"))
+	fmt.Printf("    %s               Show results as a tree grouped by directory (implies %s)\n", ui.Colors.Cyan("--tree"), ui.Colors.Cyan("--stable"))
+	fmt.Printf("    %s          Choose a result by number instead of with the picker\n", ui.Colors.Cyan("--no-picker"))
+	fmt.Printf("    %s     Stop searching after a duration, e.g. %s (exit code 3)\n",
+		ui.Colors.Cyan("--timeout DUR"), ui.Colors.Yellow("5s"))
+	fmt.Printf("    %s         List paths that could not be read\n", ui.Colors.Cyan("--show-errors"))
+	fmt.Printf("    %s     Filter by git state: %s, %s, %s, %s, %s\n",
+		ui.Colors.Cyan("--git STATE"), ui.Colors.Yellow("tracked"), ui.Colors.Yellow("modified"),
+		ui.Colors.Yellow("untracked"), ui.Colors.Yellow("staged"), ui.Colors.Yellow("ignored"))
+	fmt.Printf("    %s          Show a git status column (%s, %s, %s)\n",
+		ui.Colors.Cyan("--git-status"), ui.Colors.Yellow("M"), ui.Colors.Yellow("A"), ui.Colors.Yellow("??"))
+	fmt.Printf("    %s             Only show empty files and directories\n", ui.Colors.Cyan("--empty"))
+	fmt.Printf("    %s      Only show symlinks whose target is missing\n", ui.Colors.Cyan("--broken-links"))
+	fmt.Printf("    %s         Filter by content: %s, %s, %s, %s, %s,\n",
+		ui.Colors.Cyan("--kind KIND"), ui.Colors.Yellow("image"), ui.Colors.Yellow("video"),
+		ui.Colors.Yellow("audio"), ui.Colors.Yellow("archive"), ui.Colors.Yellow("pdf"))
+	fmt.Printf("    %s %s, %s, %s, %s (read from the file, not the extension)\n",
+		strings.Repeat(" ", 20), ui.Colors.Yellow("text"), ui.Colors.Yellow("binary"),
+		ui.Colors.Yellow("elf"), ui.Colors.Yellow("script"))
+	fmt.Printf("    %s         When to use colors: %s (default), %s, %s\n",
+		ui.Colors.Cyan("--color WHEN"), ui.Colors.Yellow("auto"), ui.Colors.Yellow("always"), ui.Colors.Yellow("never"))
+	fmt.Printf("    %s          Icons: %s (default), %s (Nerd Font), %s, %s\n",
+		ui.Colors.Cyan("--icons SET"), ui.Colors.Yellow("emoji"), ui.Colors.Yellow("nerd"), ui.Colors.Yellow("ascii"), ui.Colors.Yellow("none"))
+	fmt.Printf("    %s              Print bare paths only (default when output is piped)\n", ui.Colors.Cyan("--plain"))
+	fmt.Printf("    %s         Print bare paths separated by NUL, for %s\n", ui.Colors.Cyan("-0, --print0"), ui.Colors.Yellow("xargs -0"))
+	fmt.Printf("    %s    Print each result with a template, e.g. %s\n",
+		ui.Colors.Cyan("--format TEMPLATE"), ui.Colors.Yellow(`'{path}\t{size:h}\t{mtime:2006-01-02}'`))
+	fmt.Printf("    %s Fields: %s\n", strings.Repeat(" ", 20), ui.Colors.Yellow(strings.Join(ui.FormatFields, ", ")))
+	fmt.Printf("    %s                Print results as CSV with a header row\n", ui.Colors.Cyan("--csv"))
+	fmt.Printf("    %s                Print results as TSV with a header row\n", ui.Colors.Cyan("--tsv"))
+	fmt.Printf("    %s               Print results and summary as one JSON document\n", ui.Colors.Cyan("--json"))
+	fmt.Printf("    %s             Print one JSON object per result, as found\n", ui.Colors.Cyan("--ndjson"))
+	fmt.Printf("    %s    Run CMD for each result, in parallel\n", ui.Colors.Cyan("-x, --exec CMD"))
+	fmt.Printf("    %s Run CMD once with all results\n", ui.Colors.Cyan("-X, --exec-batch CMD"))
+	fmt.Printf("    %s     Parallel --exec commands (default: CPU count)\n", ui.Colors.Cyan("-j, --jobs N"))
+	fmt.Printf("    %s Placeholders: %s path, %s name, %s parent, %s no extension, %s name without extension\n",
+		strings.Repeat(" ", 20), ui.Colors.Yellow("{}"), ui.Colors.Yellow("{/}"), ui.Colors.Yellow("{//}"),
+		ui.Colors.Yellow("{.}"), ui.Colors.Yellow("{/.}"))
+	fmt.Printf("    %s      Copy results into DIR\n", ui.Colors.Cyan("--copy-to DIR"))
+	fmt.Printf("    %s      Move results into DIR\n", ui.Colors.Cyan("--move-to DIR"))
+	fmt.Printf("    %s            Delete results (asks for confirmation)\n", ui.Colors.Cyan("--delete"))
+	fmt.Printf("    %s   When a target exists: %s (default), %s, %s\n",
+		ui.Colors.Cyan("--conflict POLICY"), ui.Colors.Yellow("skip"), ui.Colors.Yellow("overwrite"), ui.Colors.Yellow("rename"))
+	fmt.Printf("    %s           Only preview what would be copied, moved or deleted\n", ui.Colors.Cyan("--dry-run"))
 	fmt.Println()
 	fmt.Println(ui.Colors.Bold("COMMANDS:"))
 	fmt.Printf("    %s              Install fcf to system (requires sudo/admin)\n", ui.Colors.Cyan("install"))
 	fmt.Printf("    %s               Update fcf to the latest version\n", ui.Colors.Cyan("update"))
 	fmt.Printf("    %s            Remove fcf from system\n", ui.Colors.Cyan("uninstall"))
+	fmt.Printf("    %s            Largest directories and files under PATH (%s)\n",
+		ui.Colors.Cyan("du [PATH]"), ui.Colors.Yellow("--top N"))
 	fmt.Println()
 	fmt.Println(ui.Colors.Bold("SHELL INTEGRATION (for navigation to work):"))
 	showShellIntegrationHelp()
@@ -58,17 +116,60 @@ func ShowHelp() {
 	fmt.Printf("    %s\n", ui.Colors.Green("# Find with file sizes"))
 	fmt.Println("    fcf --show-size \"*.mp4\"")
 	fmt.Println()
+	fmt.Printf("    %s\n", ui.Colors.Green("# Find Go files you have changed but not committed"))
+	fmt.Println("    fcf --git modified --git-status \"*.go\"")
+	fmt.Println()
+	fmt.Printf("    %s\n", ui.Colors.Green("# Compress every log file, four at a time"))
+	fmt.Println("    fcf -j 4 --exec 'gzip {}' \"*.log\"")
+	fmt.Println()
+	fmt.Printf("    %s\n", ui.Colors.Green("# Preview moving old logs into an archive"))
+	fmt.Println("    fcf --move-to ~/archive --dry-run 'name:*.log mtime:>30d'")
+	fmt.Println()
+	fmt.Printf("    %s\n", ui.Colors.Green("# Remove empty directories and dangling symlinks"))
+	fmt.Println("    fcf --empty --broken-links --delete")
+	fmt.Println()
+	fmt.Printf("    %s\n", ui.Colors.Green("# Remove log files from a script"))
+	fmt.Println("    fcf -0 \"*.log\" | xargs -0 rm")
+	fmt.Println()
+	fmt.Printf("    %s\n", ui.Colors.Green("# Report Go files with size and date"))
+	fmt.Println("    fcf --format '{size}\\t{mtime:2006-01-02}\\t{path}' \"*.go\"")
+	fmt.Println()
+	fmt.Printf("    %s\n", ui.Colors.Green("# List large log files with jq"))
+	fmt.Println("    fcf --json \"*.log\" | jq '.results[] | select(.size > 1048576) | .path'")
+	fmt.Println()
+	fmt.Printf("    %s\n", ui.Colors.Green("# Stop after the first 10 matches"))
+	fmt.Println("    fcf --max-results 10 \"*.go\"")
+	fmt.Println()
+	fmt.Println(ui.Colors.Bold("QUERY SYNTAX:"))
+	fmt.Println("    Combine criteria in the pattern, e.g.")
+	fmt.Println(ui.Colors.Yellow("    fcf 'name:*.log size:>10M mtime:<7d type:f -path:node_modules'"))
+	fmt.Printf("    %s GLOB    %s TEXT    %s go,rs    %s f|d|l|x    %s git state\n",
+		ui.Colors.Cyan("name:"), ui.Colors.Cyan("path:"), ui.Colors.Cyan("ext:"), ui.Colors.Cyan("type:"), ui.Colors.Cyan("git:"))
+	fmt.Printf("    %s also takes %s and %s\n", ui.Colors.Cyan("type:"), ui.Colors.Yellow("empty"), ui.Colors.Yellow("broken"))
+	fmt.Printf("    %s image|video|audio|archive|pdf|text|binary|elf|script\n", ui.Colors.Cyan("kind:"))
+	fmt.Printf("    %s >10M, <=500K    %s <7d, >1y, >2024-01-31\n", ui.Colors.Cyan("size:"), ui.Colors.Cyan("mtime:"))
+	fmt.Printf("    Terms are ANDed; use %s or %s, %s or %s, and %s to group\n",
+		ui.Colors.Cyan("OR"), ui.Colors.Cyan("|"), ui.Colors.Cyan("NOT"), ui.Colors.Cyan("-"), ui.Colors.Cyan("( )"))
+	fmt.Println()
 	fmt.Println(ui.Colors.Bold("INTERACTIVE WORKFLOW:"))
 	fmt.Println("    Step 1: Enter path to search")
 	fmt.Println("    Step 2: Enter pattern to find")
-	fmt.Println("    Step 3: Navigate to a result path")
+	fmt.Println("    Step 3: Pick a result to navigate to (arrows or j/k, type to filter, Esc to skip)")
 	fmt.Println()
 	fmt.Println(ui.Colors.Bold("NAVIGATION OPTIONS:"))
 	fmt.Println("    After navigation, choose:")
 	fmt.Printf("    %s - Find again (restart from Step 1)\n", ui.Colors.Cyan("f"))
 	fmt.Printf("    %s - Repeat search (go to Step 2, same path)\n", ui.Colors.Cyan("r"))
+	fmt.Printf("    %s - Run a command on the results\n", ui.Colors.Cyan("x"))
+	fmt.Printf("    %s - Copy, move or delete the results\n", ui.Colors.Cyan("a"))
 	fmt.Printf("    %s - Exit\n", ui.Colors.Cyan("n"))
 	fmt.Println()
+	fmt.Println(ui.Colors.Bold("CONFIGURATION:"))
+	fmt.Printf("    Defaults are read from %s (override with %s)\n",
+		ui.Colors.Cyan(config.GetPath()), ui.Colors.Cyan("FCF_CONFIG"))
+	fmt.Println("    One \"key = value\" per line, for example:")
+	fmt.Println(ui.Colors.Yellow("    smart-case = true"))
+	fmt.Println()
 	fmt.Println(ui.Colors.Bold("PERFORMANCE:"))
 	fmt.Println("    - Uses 'fd' for fast parallel searching (if installed)")
 	fmt.Println("    - Falls back to Go's filepath.WalkDir if fd is not available")
@@ -2,13 +2,16 @@ package command
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 
+	"github.com/ReggieAlbiosA/fcf/internal/input"
 	"github.com/ReggieAlbiosA/fcf/internal/navigation"
 	"github.com/ReggieAlbiosA/fcf/internal/search"
+	"github.com/ReggieAlbiosA/fcf/internal/store"
 	"github.com/ReggieAlbiosA/fcf/internal/ui"
 )
 
@@ -21,6 +24,17 @@ func readLine(prompt string) string {
 	return strings.TrimSpace(input)
 }
 
+// expandPath expands ~ and environment variables in a user-supplied path
+func expandPath(path string) string {
+	if strings.HasPrefix(path, "~") {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			path = strings.Replace(path, "~", home, 1)
+		}
+	}
+	return os.ExpandEnv(path)
+}
+
 // getSearchPath prompts for and returns the search path (Step 1)
 func getSearchPath() string {
 	cwd, _ := os.Getwd()
@@ -29,28 +43,19 @@ func getSearchPath() string {
 	fmt.Printf("%s\n", ui.Colors.Dim(fmt.Sprintf("(Press Enter for current directory: %s)", cwd)))
 	fmt.Println()
 
-	userPath := readLine(ui.Colors.Cyan("Path: "))
+	userPath := readLine(ui.Colors.Prompt("Path: "))
 
 	if userPath == "" {
 		fmt.Println(ui.Colors.Green("Using current directory"))
 		return "."
 	}
 
-	// Expand ~ to home directory
-	if strings.HasPrefix(userPath, "~") {
-		home, err := os.UserHomeDir()
-		if err == nil {
-			userPath = strings.Replace(userPath, "~", home, 1)
-		}
-	}
-
-	// Expand environment variables
-	userPath = os.ExpandEnv(userPath)
+	userPath = expandPath(userPath)
 
 	// Validate path exists
 	info, err := os.Stat(userPath)
 	if err != nil || !info.IsDir() {
-		fmt.Printf("%s Directory '%s' does not exist\n", ui.Colors.Red("ERROR:"), userPath)
+		fmt.Printf("%s Directory '%s' does not exist\n", ui.Colors.Error("ERROR:"), userPath)
 		readLine("Press Enter to try again...")
 		return ""
 	}
@@ -63,28 +68,53 @@ func getSearchPath() string {
 func getPattern() string {
 	fmt.Printf("%s Enter file/folder name or pattern to find\n", ui.Colors.Bold("Step 2:"))
 	fmt.Printf("%s\n", ui.Colors.Dim("Examples: *.log, config, .env, src, *.js"))
+	fmt.Printf("%s\n", ui.Colors.Dim("Query:    name:*.log size:>10M mtime:<7d type:f -path:node_modules"))
 	fmt.Println()
 
-	pattern := readLine(ui.Colors.Cyan("Pattern: "))
+	pattern := readLine(ui.Colors.Prompt("Pattern: "))
 
 	if pattern == "" {
-		fmt.Printf("%s Pattern cannot be empty\n", ui.Colors.Red("ERROR:"))
+		fmt.Printf("%s Pattern cannot be empty\n", ui.Colors.Error("ERROR:"))
 		readLine("Press Enter to try again...")
 		return ""
 	}
 
+	// Point at the position of a query syntax error; the pattern is then
+	// matched as a plain name, unless a new one is typed
+	if search.LooksLikeQuery(pattern) {
+		var queryErr *search.QueryError
+		if err := search.ValidateQuery(pattern); errors.As(err, &queryErr) {
+			fmt.Printf("%s%s\n", strings.Repeat(" ", len("Pattern: ")+queryErr.Pos), ui.Colors.Yellow("^"))
+			fmt.Printf("%s not a valid query: %s\n", ui.Colors.Yellow("Warning:"), queryErr.Msg)
+			if retry := readLine(ui.Colors.Prompt("New pattern (Enter to match it as a name): ")); retry != "" {
+				pattern = retry
+			}
+		}
+	}
+
 	fmt.Println()
 	return pattern
 }
 
 // SelectResult prompts user to select a result for navigation (Step 3)
-func SelectResult(results []string) string {
+// On a terminal the results are picked from a full-screen list; otherwise,
+// or with --no-picker, a result number or path is typed
+func SelectResult(results *store.Paths) string {
+	if !ui.Opts.NoPicker && input.IsInteractive() {
+		if path, err := ui.PickResult(results); err == nil {
+			if path == "" {
+				fmt.Println(ui.Colors.Dim("Skipped navigation"))
+			}
+			return path
+		}
+	}
+
 	fmt.Println()
 	fmt.Printf("%s Enter path to navigate to\n", ui.Colors.Bold("Step 3:"))
 	fmt.Printf("%s\n", ui.Colors.Dim("(Enter a number from results, full path, or press Enter to skip)"))
 	fmt.Println()
 
-	navInput := readLine(ui.Colors.Cyan("Navigate to: "))
+	navInput := readLine(ui.Colors.Prompt("Navigate to: "))
 
 	if navInput == "" {
 		fmt.Println(ui.Colors.Dim("Skipped navigation"))
@@ -94,43 +124,48 @@ func SelectResult(results []string) string {
 	// Check if input is a number (result index)
 	if index, err := strconv.Atoi(navInput); err == nil {
 		idx := index - 1 // Convert to 0-based index
-		if idx >= 0 && idx < len(results) {
-			return results[idx]
+		if idx >= 0 && idx < results.Len() {
+			return results.At(idx)
 		}
-		fmt.Printf("%s Invalid result number\n", ui.Colors.Red("ERROR:"))
+		fmt.Printf("%s Invalid result number\n", ui.Colors.Error("ERROR:"))
 		return ""
 	}
 
-	// Expand ~ and environment variables
-	if strings.HasPrefix(navInput, "~") {
-		home, err := os.UserHomeDir()
-		if err == nil {
-			navInput = strings.Replace(navInput, "~", home, 1)
-		}
-	}
-	navInput = os.ExpandEnv(navInput)
-
-	return navInput
+	return expandPath(navInput)
 }
 
 // showOptionsMenu displays the options menu and returns user choice
-func showOptionsMenu() int {
+func showOptionsMenu(hasResults bool) int {
 	fmt.Println()
 	fmt.Println(ui.Colors.Bold("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
 	fmt.Println(ui.Colors.Bold("Options:"))
 	fmt.Printf("  %s Find again (new search)\n", ui.Colors.Cyan("[f]"))
 	fmt.Printf("  %s Repeat search (same path)\n", ui.Colors.Cyan("[r]"))
+	if hasResults {
+		fmt.Printf("  %s Run command on results\n", ui.Colors.Cyan("[x]"))
+		fmt.Printf("  %s Copy, move or delete results\n", ui.Colors.Cyan("[a]"))
+	}
 	fmt.Printf("  %s Exit\n", ui.Colors.Cyan("[n]"))
 	fmt.Println(ui.Colors.Bold("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
 	fmt.Println()
 
-	choice := readLine(ui.Colors.Cyan("Choose: "))
+	choice := readLine(ui.Colors.Prompt("Choose: "))
 
 	switch strings.ToLower(choice) {
 	case "f":
 		return 1 // Go to Step 1
 	case "r":
 		return 2 // Go to Step 2
+	case "x":
+		if hasResults {
+			return 3 // Run command, then show the menu again
+		}
+		return 0
+	case "a":
+		if hasResults {
+			return 4 // File action, then show the menu again
+		}
+		return 0
 	default:
 		return 0 // Exit
 	}
@@ -141,9 +176,14 @@ func RunInteractiveMode() {
 	currentStep := 1
 	var searchPath, pattern string
 
+	// Without fd, list shallow matches first so likely targets get low numbers
+	if !bfsChosen && !search.HasFd() {
+		ui.Opts.BFS = true
+	}
+
 	for {
 		// Reset results
-		var results []string
+		var results *store.Paths
 
 		// Show header
 		ui.ShowHeader()
@@ -171,15 +211,19 @@ func RunInteractiveMode() {
 
 		// Execute search
 		startTime := getTime()
-		searchResult, _ := search.SearchWithStop(pattern, searchPath)
+		searchResult, err := search.SearchWithStop(pattern, searchPath)
 		elapsed := getTime() - startTime
 		results = searchResult.Results
 
 		// Show summary
-		ui.ShowSummaryWithStatus(len(results), elapsed, searchResult.Stopped)
+		ui.ShowSummaryWithStatus(results.Len(), elapsed, searchResult.Status())
+		ui.ShowErrors(searchResult.Errors, ui.Opts.ShowErrors)
+		if err != nil {
+			fmt.Printf("%s %v\n", ui.Colors.Error("ERROR:"), err)
+		}
 
 		// Step 3: Navigate to path
-		if len(results) > 0 {
+		if results.Len() > 0 {
 			targetPath := SelectResult(results)
 			if targetPath != "" {
 				fmt.Println()
@@ -188,7 +232,16 @@ func RunInteractiveMode() {
 		}
 
 		// Show options menu
-		choice := showOptionsMenu()
+		choice := showOptionsMenu(results.Len() > 0)
+		for choice == 3 || choice == 4 {
+			if choice == 3 {
+				promptCommand(results)
+			} else {
+				promptFileAction(results, searchPath)
+			}
+			choice = showOptionsMenu(true)
+		}
+		results.Close()
 
 		switch choice {
 		case 0: // Exit
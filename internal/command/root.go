@@ -4,21 +4,41 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/ReggieAlbiosA/fcf/internal/config"
+	"github.com/ReggieAlbiosA/fcf/internal/fileops"
+	"github.com/ReggieAlbiosA/fcf/internal/git"
+	"github.com/ReggieAlbiosA/fcf/internal/icons"
 	"github.com/ReggieAlbiosA/fcf/internal/install"
+	"github.com/ReggieAlbiosA/fcf/internal/kind"
 	"github.com/ReggieAlbiosA/fcf/internal/navigation"
+	"github.com/ReggieAlbiosA/fcf/internal/runner"
 	"github.com/ReggieAlbiosA/fcf/internal/search"
+	"github.com/ReggieAlbiosA/fcf/internal/store"
 	"github.com/ReggieAlbiosA/fcf/internal/ui"
 )
 
 // Options definition and Opts var removed (moved to ui package)
 
+// Exit codes for direct mode
+const (
+	exitNoMatches   = 1   // the search found nothing
+	exitError       = 2   // the search could not run
+	exitTimedOut    = 3   // --timeout cut the search short
+	exitInterrupted = 130 // Ctrl+C or SIGTERM stopped fcf
+)
+
 // timeNow is a wrapper for time.Now (for testing)
 var timeNow = time.Now
 
 // Execute parses arguments and runs the appropriate command
 func Execute() {
+	closeStoresOnInterrupt()
 	parseArgs()
 
 	// Show help if requested
@@ -29,54 +49,295 @@ func Execute() {
 
 	// If pattern provided, run single search; otherwise interactive mode
 	if ui.Opts.Pattern != "" {
-		runSingleSearch()
+		if code := runSingleSearch(); code != 0 {
+			os.Exit(code)
+		}
 	} else {
 		RunInteractiveMode()
 	}
 }
 
+// closeStoresOnInterrupt makes Ctrl+C remove the results' spill files
+// before fcf exits, as it still does at once
+func closeStoresOnInterrupt() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		store.CloseAll()
+		os.Exit(exitInterrupted)
+	}()
+}
+
+// outputFlags lists the flags that print results for other programs
+const outputFlags = "--plain, --print0, --format, --csv, --tsv, --json and --ndjson"
+
+// bfsChosen is set when --bfs was given or configured
+var bfsChosen bool
+
 func parseArgs() {
+	// Config file values become the flag defaults
+	cfg := config.Load()
+
 	flag.BoolVar(&ui.Opts.Help, "h", false, "Show help message")
 	flag.BoolVar(&ui.Opts.Help, "help", false, "Show help message")
 	flag.BoolVar(&ui.Opts.IgnoreCase, "i", false, "Case-insensitive pattern matching")
+	flag.BoolVar(&ui.Opts.CaseSensitive, "s", false, "Case-sensitive pattern matching")
+	flag.BoolVar(&ui.Opts.CaseSensitive, "case-sensitive", false, "Case-sensitive pattern matching")
+	flag.BoolVar(&ui.Opts.SmartCase, "smart-case", cfg.Bool("smart-case", false), "Case-insensitive unless the pattern has uppercase letters")
 	flag.StringVar(&ui.Opts.Type, "t", "", "Filter by type: 'f' for files, 'd' for directories")
 	flag.BoolVar(&ui.Opts.ShowSize, "show-size", false, "Display file sizes")
+	flag.BoolVar(&ui.Opts.DirSizes, "dir-sizes", false, "Display recursive directory sizes")
 	flag.IntVar(&ui.Opts.MaxDisplay, "max-display", 0, "Maximum results to display (0 = unlimited)")
+	flag.IntVar(&ui.Opts.MaxResults, "max-results", 0, "Stop searching after NUM matches (0 = unlimited)")
+	flag.BoolVar(&ui.Opts.BFS, "bfs", cfg.Bool("bfs", false), "Search level by level so shallow matches come first")
+	flag.BoolVar(&ui.Opts.Stable, "stable", cfg.Bool("stable", false), "Show results in the same order on every run")
+	flag.Var(&ui.Opts.Relative, "relative", "Show paths relative to the search root, or with =cwd to the working directory")
+	flag.BoolVar(&ui.Opts.Hyperlink, "hyperlink", false, "Make results clickable links to their files (OSC 8)")
+	flag.BoolVar(&ui.Opts.Long, "l", false, "Show permissions, links, owner, group, size and date")
+	flag.BoolVar(&ui.Opts.Long, "long", false, "Show permissions, links, owner, group, size and date")
+	flag.BoolVar(&ui.Opts.Tree, "tree", false, "Show results as a tree grouped by directory")
+	flag.BoolVar(&ui.Opts.NoPicker, "no-picker", !cfg.Bool("picker", true), "Choose a result by number instead of with the full-screen picker")
+	var first bool
+	flag.BoolVar(&first, "1", false, "Stop searching after the first match")
+	flag.BoolVar(&first, "first", false, "Stop searching after the first match")
+	flag.DurationVar(&ui.Opts.Timeout, "timeout", 0, "Stop searching after this long, e.g. 5s (0 = no limit)")
+	flag.BoolVar(&ui.Opts.ShowErrors, "show-errors", false, "List paths that could not be read")
+	flag.StringVar(&ui.Opts.Git, "git", "", "Only show paths with this git state: tracked, modified, untracked, staged, ignored")
+	flag.BoolVar(&ui.Opts.GitStatus, "git-status", false, "Show a git status column (M, A, ??)")
+	flag.BoolVar(&ui.Opts.Empty, "empty", false, "Only show empty files and directories")
+	flag.BoolVar(&ui.Opts.BrokenLinks, "broken-links", false, "Only show symlinks whose target is missing")
+	flag.StringVar(&ui.Opts.Kind, "kind", "", "Only show files whose content is: image, video, audio, archive, pdf, text, binary, elf, script")
+	flag.StringVar(&ui.Opts.Exec, "x", "", "Run a command for each result ({} is the path)")
+	flag.StringVar(&ui.Opts.Exec, "exec", "", "Run a command for each result ({} is the path)")
+	flag.StringVar(&ui.Opts.ExecBatch, "X", "", "Run a command once with all results")
+	flag.StringVar(&ui.Opts.ExecBatch, "exec-batch", "", "Run a command once with all results")
+	flag.StringVar(&ui.Opts.Color, "color", cfg.String("color", "auto"), "When to use colors: auto, always or never")
+	flag.StringVar(&ui.Opts.Icons, "icons", cfg.String("icons", string(icons.Emoji)), "Icon set: emoji, nerd, ascii or none")
+	flag.BoolVar(&ui.Opts.Plain, "plain", false, "Print bare paths, one per line (default when output is piped)")
+	flag.BoolVar(&ui.Opts.Print0, "0", false, "Print bare paths separated by NUL, for xargs -0")
+	flag.BoolVar(&ui.Opts.Print0, "print0", false, "Print bare paths separated by NUL, for xargs -0")
+	flag.StringVar(&ui.Opts.Format, "format", "", "Print each result with a template, e.g. '{path}\\t{size:h}'")
+	flag.BoolVar(&ui.Opts.CSV, "csv", false, "Print results as CSV with a header row")
+	flag.BoolVar(&ui.Opts.TSV, "tsv", false, "Print results as TSV with a header row")
+	flag.BoolVar(&ui.Opts.JSON, "json", false, "Print results and summary as one JSON document")
+	flag.BoolVar(&ui.Opts.NDJSON, "ndjson", false, "Print one JSON object per result")
+	flag.IntVar(&ui.Opts.Jobs, "j", runtime.NumCPU(), "Number of --exec commands to run in parallel")
+	flag.IntVar(&ui.Opts.Jobs, "jobs", runtime.NumCPU(), "Number of --exec commands to run in parallel")
+	flag.StringVar(&ui.Opts.CopyTo, "copy-to", "", "Copy results into DIR")
+	flag.StringVar(&ui.Opts.MoveTo, "move-to", "", "Move results into DIR")
+	flag.BoolVar(&ui.Opts.Delete, "delete", false, "Delete results")
+	flag.StringVar(&ui.Opts.Conflict, "conflict", fileops.ConflictSkip, "When a target exists: skip, overwrite or rename")
+	flag.BoolVar(&ui.Opts.DryRun, "dry-run", false, "Preview --copy-to, --move-to or --delete without changing anything")
 
 	flag.Parse()
 
+	// Interactive mode searches breadth-first with the walker unless told otherwise
+	bfsChosen = cfg.String("bfs", "") != ""
+	bfsFlag := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "bfs" {
+			bfsChosen = true
+			bfsFlag = ui.Opts.BFS
+		}
+	})
+
+	// A tree is numbered top to bottom, so it needs depth-first stable order
+	if ui.Opts.Tree {
+		if bfsFlag {
+			fmt.Fprintf(os.Stderr, "%s Use only one of --bfs and --tree\n", ui.Colors.Error("ERROR:"))
+			os.Exit(exitError)
+		}
+		ui.Opts.BFS = false
+		ui.Opts.Stable = true
+		bfsChosen = true
+	}
+
+	// Colors were set up before the flags were read
+	initColors()
+
+	ui.Opts.Icons = strings.ToLower(ui.Opts.Icons)
+	if !icons.IsValid(ui.Opts.Icons) {
+		fmt.Fprintf(os.Stderr, "%s Invalid --icons value '%s' (use emoji, nerd, ascii, none)\n",
+			ui.Colors.Error("ERROR:"), ui.Opts.Icons)
+		os.Exit(exitError)
+	}
+
+	if ui.Opts.Git != "" && !git.IsValidFilter(ui.Opts.Git) {
+		fmt.Fprintf(os.Stderr, "%s Invalid --git value '%s' (use %s)\n",
+			ui.Colors.Error("ERROR:"), ui.Opts.Git, strings.Join(git.Filters, ", "))
+		os.Exit(exitError)
+	}
+
+	ui.Opts.Kind = strings.ToLower(ui.Opts.Kind)
+	if ui.Opts.Kind != "" && !kind.IsValid(ui.Opts.Kind) {
+		fmt.Fprintf(os.Stderr, "%s Invalid --kind value '%s' (use image, video, audio, archive, pdf, text, binary, elf, script)\n",
+			ui.Colors.Error("ERROR:"), ui.Opts.Kind)
+		os.Exit(exitError)
+	}
+
+	if !fileops.IsValidConflict(ui.Opts.Conflict) {
+		fmt.Fprintf(os.Stderr, "%s Invalid --conflict value '%s' (use skip, overwrite, rename)\n",
+			ui.Colors.Error("ERROR:"), ui.Opts.Conflict)
+		os.Exit(exitError)
+	}
+
+	actions := 0
+	for _, set := range []bool{ui.Opts.Exec != "" || ui.Opts.ExecBatch != "", ui.Opts.CopyTo != "", ui.Opts.MoveTo != "", ui.Opts.Delete} {
+		if set {
+			actions++
+		}
+	}
+	if actions > 1 {
+		fmt.Fprintf(os.Stderr, "%s Use only one of --exec, --exec-batch, --copy-to, --move-to and --delete\n", ui.Colors.Error("ERROR:"))
+		os.Exit(exitError)
+	}
+
+	formats := 0
+	for _, set := range []bool{ui.Opts.Plain || ui.Opts.Print0, ui.Opts.Format != "", ui.Opts.CSV, ui.Opts.TSV, ui.Opts.JSON, ui.Opts.NDJSON} {
+		if set {
+			formats++
+		}
+	}
+	if formats > 1 {
+		fmt.Fprintf(os.Stderr, "%s Use only one of %s\n", ui.Colors.Error("ERROR:"), outputFlags)
+		os.Exit(exitError)
+	}
+	if err := ui.SetFormat(ui.Opts.Format, ui.Opts.CSV, ui.Opts.TSV); err != nil {
+		fmt.Fprintf(os.Stderr, "%s Invalid --format: %v\n", ui.Colors.Error("ERROR:"), err)
+		os.Exit(exitError)
+	}
+	if ui.Scripted() && actions > 0 {
+		fmt.Fprintf(os.Stderr, "%s %s cannot be combined with --exec, --copy-to, --move-to or --delete\n", ui.Colors.Error("ERROR:"), outputFlags)
+		os.Exit(exitError)
+	}
+
+	if first {
+		ui.Opts.MaxResults = 1
+	}
+
 	// Get positional arguments
 	args := flag.Args()
+	if len(args) == 0 && ui.Scripted() && !ui.Opts.Empty && !ui.Opts.BrokenLinks {
+		fmt.Fprintf(os.Stderr, "%s %s need a pattern\n", ui.Colors.Error("ERROR:"), outputFlags)
+		os.Exit(exitError)
+	}
 	if len(args) >= 1 {
 		ui.Opts.Pattern = args[0]
+	} else if ui.Opts.Empty || ui.Opts.BrokenLinks {
+		// Cleanup searches need no pattern
+		ui.Opts.Pattern = "*"
 	}
 	if len(args) >= 2 {
 		ui.Opts.Path = args[1]
 	} else {
 		ui.Opts.Path = "."
 	}
+
+	// A direct search whose output goes to a pipe or file prints bare paths,
+	// unless another format or an action was asked for
+	if ui.Opts.Pattern != "" && !ui.Scripted() && actions == 0 && !ui.IsTerminal() {
+		ui.Opts.Plain = true
+	}
 }
 
-func runSingleSearch() {
+// initColors checks the --color value and sets up colors for it, exiting
+// on an unknown mode
+func initColors() {
+	ui.Opts.Color = strings.ToLower(ui.Opts.Color)
+	if !ui.IsValidColorMode(ui.Opts.Color) {
+		fmt.Fprintf(os.Stderr, "%s Invalid --color value '%s' (use %s)\n",
+			ui.Colors.Error("ERROR:"), ui.Opts.Color, strings.Join(ui.ColorModes, ", "))
+		os.Exit(exitError)
+	}
+	ui.InitColors()
+}
+
+// runSingleSearch runs a search from the command line and returns the exit code
+func runSingleSearch() int {
 	ui.ShowHeader()
 
 	startTime := getTime()
-	result, _ := search.SearchWithStop(ui.Opts.Pattern, ui.Opts.Path)
+	result, err := search.SearchWithStop(ui.Opts.Pattern, ui.Opts.Path)
 	elapsed := getTime() - startTime
+	defer result.Results.Close()
+	count := result.Results.Len()
 
-	ui.ShowSummaryWithStatus(len(result.Results), elapsed, result.Stopped)
+	if err != nil && count == 0 {
+		showSearchError(err)
+		return exitError
+	}
 
-	// If results found, offer navigation
-	if len(result.Results) > 0 {
+	if ui.Scripted() {
+		// Scripted output is never followed by a summary or prompt; JSON carries its own
+		ui.ShowJSONSummary(count, elapsed, result.Status(), result.Errors, err)
+		if !ui.Opts.JSON {
+			ui.ShowErrors(result.Errors, ui.Opts.ShowErrors)
+			if err != nil {
+				showSearchError(err)
+			}
+		}
+		return searchExitCode(result, err)
+	}
+
+	ui.ShowSummaryWithStatus(count, elapsed, result.Status())
+	ui.ShowErrors(result.Errors, ui.Opts.ShowErrors)
+	if err != nil {
+		showSearchError(err)
+	}
+
+	// Run the requested command instead of offering navigation
+	if ui.Opts.Exec != "" || ui.Opts.ExecBatch != "" {
+		if count > 0 {
+			batch := ui.Opts.ExecBatch != ""
+			template := ui.Opts.Exec
+			if batch {
+				template = ui.Opts.ExecBatch
+			}
+			statuses := runCommand(template, batch, result.Results)
+			if len(runner.Failed(statuses)) > 0 {
+				return exitError
+			}
+		}
+	} else if action, destDir, ok := fileAction(); ok {
+		if count > 0 && !runFileAction(action, result.Results, destDir, ui.Opts.Conflict, ui.Opts.Path) {
+			return exitError
+		}
+	} else if count > 0 {
+		// If results found, offer navigation
 		targetPath := SelectResult(result.Results)
 		if targetPath != "" {
 			fmt.Println()
 			navigation.NavigateToPath(targetPath)
 		}
 	}
+
+	return searchExitCode(result, err)
 }
 
+// searchExitCode signals failed, truncated or empty output to scripts
+func searchExitCode(result *search.SearchResult, err error) int {
+	if err != nil {
+		return exitError
+	}
+	if result.TimedOut {
+		return exitTimedOut
+	}
+	if result.Results.Len() == 0 {
+		return exitNoMatches
+	}
+	return 0
+}
 
+// showSearchError prints an error that ended the search, on stderr when
+// stdout carries scripted output
+func showSearchError(err error) {
+	if ui.Scripted() {
+		fmt.Fprintf(os.Stderr, "%s %v\n", ui.Colors.Error("ERROR:"), err)
+		return
+	}
+	fmt.Printf("%s %v\n", ui.Colors.Error("ERROR:"), err)
+}
 
 // RunInstall is called from main for the install command
 func RunInstall() {
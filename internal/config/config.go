@@ -0,0 +1,96 @@
+package config
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Config holds default option values read from the config file
+// Command-line flags always override these defaults
+type Config map[string]string
+
+// GetPath returns the path to the config file
+// FCF_CONFIG overrides the default location (e.g. ~/.config/fcf/config)
+func GetPath() string {
+	if path := os.Getenv("FCF_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "fcf", "config")
+}
+
+// ThemePath returns the path to the theme file, next to the config file
+func ThemePath() string {
+	path := GetPath()
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), "theme")
+}
+
+// Load reads the config file
+func Load() Config {
+	return LoadFile(GetPath())
+}
+
+// LoadFile reads a file of settings
+// The format is one "key = value" per line; blank lines and lines starting
+// with # are ignored. A missing file yields an empty Config
+func LoadFile(path string) Config {
+	cfg := Config{}
+
+	if path == "" {
+		return cfg
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return cfg
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		cfg[key] = value
+	}
+
+	return cfg
+}
+
+// Bool returns the boolean value of key, or def if unset or invalid
+func (c Config) Bool(key string, def bool) bool {
+	value, ok := c[key]
+	if !ok {
+		return def
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// String returns the value of key, or def if unset
+func (c Config) String(key, def string) string {
+	if value, ok := c[key]; ok {
+		return value
+	}
+	return def
+}
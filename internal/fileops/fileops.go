@@ -0,0 +1,529 @@
+package fileops
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ReggieAlbiosA/fcf/internal/platform"
+	"github.com/ReggieAlbiosA/fcf/internal/store"
+	"github.com/ReggieAlbiosA/fcf/internal/ui"
+)
+
+// Action is a bulk operation on search results
+type Action int
+
+const (
+	Copy Action = iota
+	Move
+	Delete
+)
+
+// String returns the verb for the action
+func (a Action) String() string {
+	switch a {
+	case Copy:
+		return "copy"
+	case Move:
+		return "move"
+	default:
+		return "delete"
+	}
+}
+
+// Title returns the verb for the action with a capital letter
+func (a Action) Title() string {
+	s := a.String()
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// Conflict policies for targets that already exist
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictRename    = "rename"
+)
+
+// IsValidConflict reports whether policy is a known --conflict value
+func IsValidConflict(policy string) bool {
+	return policy == ConflictSkip || policy == ConflictOverwrite || policy == ConflictRename
+}
+
+// Op is one planned file operation
+type Op struct {
+	Source    string
+	Target    string // empty for Delete
+	IsDir     bool
+	Overwrite bool   // target exists and will be replaced
+	Skip      string // reason the operation is skipped, if any
+}
+
+// Result is the outcome of one operation
+type Result struct {
+	Op  Op
+	Err error
+}
+
+// Plan works out what action would do to paths without touching anything
+// Paths inside another selected directory are left to that directory, and
+// targets are checked against existing files and each other using policy
+func Plan(action Action, paths *store.Paths, destDir, policy string) []Op {
+	sources := topLevel(paths)
+	ops := make([]Op, 0, len(sources))
+	planned := map[string]bool{}
+
+	for _, src := range sources {
+		info, err := os.Lstat(src)
+		if err != nil {
+			ops = append(ops, Op{Source: src, Skip: "no longer exists"})
+			continue
+		}
+		op := Op{Source: src, IsDir: info.IsDir()}
+
+		if action == Delete {
+			ops = append(ops, op)
+			continue
+		}
+
+		target := filepath.Join(destDir, filepath.Base(src))
+		if sameFile(src, target) {
+			op.Skip = "already in destination"
+			ops = append(ops, op)
+			continue
+		}
+		if isInside(destDir, src) {
+			op.Skip = "destination is inside this directory"
+			ops = append(ops, op)
+			continue
+		}
+
+		if exists(target) || planned[target] {
+			switch policy {
+			case ConflictOverwrite:
+				if planned[target] {
+					op.Skip = "another result has the same name"
+				} else {
+					op.Overwrite = true
+				}
+			case ConflictRename:
+				target = uniqueName(target, planned)
+			default:
+				op.Skip = "target exists"
+			}
+		}
+
+		op.Target = target
+		if op.Skip == "" {
+			planned[target] = true
+		}
+		ops = append(ops, op)
+	}
+
+	return ops
+}
+
+// ShowPlan displays the dry-run preview of the planned operations
+func ShowPlan(action Action, ops []Op) {
+	fmt.Println()
+	fmt.Println(ui.Colors.Bold(fmt.Sprintf("Dry run: %s", action)))
+	fmt.Println()
+
+	for _, op := range ops {
+		source := op.Source
+		if op.IsDir {
+			source += string(filepath.Separator)
+		}
+
+		switch {
+		case op.Skip != "":
+			fmt.Printf("  %s %s %s\n", ui.Colors.Yellow("skip"), source, ui.Colors.Dim("("+op.Skip+")"))
+		case action == Delete && op.IsDir && !isEmptyDir(op.Source):
+			fmt.Printf("  %s %s %s\n", ui.Colors.Red("delete"), source, ui.Colors.Dim("(with all contents)"))
+		case action == Delete:
+			fmt.Printf("  %s %s\n", ui.Colors.Red("delete"), source)
+		default:
+			note := ""
+			if op.Overwrite {
+				note = " " + ui.Colors.Red("(overwrite)")
+			}
+			fmt.Printf("  %s %s %s %s%s\n", ui.Colors.Cyan(action.String()), source, ui.Colors.Dim("→"), op.Target, note)
+		}
+	}
+
+	pending := Pending(ops)
+	fmt.Println()
+	fmt.Printf("%s\n", ui.Colors.Dim(fmt.Sprintf("%d to %s, %d skipped", pending, action, len(ops)-pending)))
+}
+
+// Pending returns how many operations are not skipped
+func Pending(ops []Op) int {
+	n := 0
+	for _, op := range ops {
+		if op.Skip == "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Execute carries out the planned operations, continuing past failures
+func Execute(action Action, ops []Op) []Result {
+	var results []Result
+	for _, op := range ops {
+		if op.Skip != "" {
+			continue
+		}
+
+		var err error
+		switch action {
+		case Copy:
+			err = copyOp(op)
+		case Move:
+			err = moveOp(op)
+		case Delete:
+			err = os.RemoveAll(op.Source)
+		}
+		results = append(results, Result{Op: op, Err: err})
+	}
+	return results
+}
+
+// ShowResults displays how many operations succeeded and every failure
+func ShowResults(action Action, results []Result) {
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+
+	fmt.Println()
+	fmt.Println(ui.Colors.Bold("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	done := len(results) - failed
+	if failed == 0 {
+		fmt.Println(ui.Colors.Summary(fmt.Sprintf("%s: %d item(s) done", action.Title(), done)))
+	} else {
+		fmt.Printf("%s %s\n",
+			ui.Colors.Yellow(fmt.Sprintf("%s: %d item(s) done,", action.Title(), done)),
+			ui.Colors.Red(fmt.Sprintf("%d failed", failed)))
+		for _, r := range results {
+			if r.Err != nil {
+				fmt.Printf("  %s %s: %v\n", ui.Colors.Error("✗"), r.Op.Source, unwrapPathError(r.Err))
+			}
+		}
+	}
+	fmt.Println(ui.Colors.Bold("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+}
+
+// PruneEmptyParents removes directories left empty by deleted results,
+// walking up from each one but never removing root or anything above it
+func PruneEmptyParents(results []Result, root string) []Result {
+	var pruned []Result
+	seen := map[string]bool{}
+
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		for dir := filepath.Dir(r.Op.Source); dir != root && isInside(dir, root) && !seen[dir]; dir = filepath.Dir(dir) {
+			if !isEmptyDir(dir) {
+				break
+			}
+			seen[dir] = true
+			err := os.Remove(dir)
+			pruned = append(pruned, Result{Op: Op{Source: dir, IsDir: true}, Err: err})
+			if err != nil {
+				break
+			}
+		}
+	}
+	return pruned
+}
+
+// Failed reports whether any operation failed
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// copyOp copies a file, symlink or directory tree to its target
+// The copy is made under a temporary name next to the target and renamed
+// into place once complete, so a failed copy leaves any existing target as
+// it was
+func copyOp(op Op) error {
+	tmp := tempName(op.Target)
+	if err := copyPath(op.Source, tmp); err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+	if err := replace(tmp, op.Target, op.Overwrite); err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+	return nil
+}
+
+// moveOp renames the source to its target, or copies it and removes the
+// original when the target is on another filesystem
+func moveOp(op Op) error {
+	tmp := tempName(op.Target)
+	err := os.Rename(op.Source, tmp)
+	if err == nil {
+		if err := replace(tmp, op.Target, op.Overwrite); err != nil {
+			os.Rename(tmp, op.Source)
+			return err
+		}
+		return nil
+	}
+	if !platform.IsCrossDevice(err) {
+		return err
+	}
+
+	// Rename cannot cross devices: copy, then remove the original
+	if err := copyPath(op.Source, tmp); err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+	if err := os.RemoveAll(op.Source); err != nil {
+		// A file that could not be removed is untouched, so the move is
+		// undone; a directory may be partly gone, so its copy is kept
+		if !op.IsDir {
+			os.RemoveAll(tmp)
+			return err
+		}
+		if replaceErr := replace(tmp, op.Target, op.Overwrite); replaceErr != nil {
+			return fmt.Errorf("%w (the copy is in %s)", err, tmp)
+		}
+		return fmt.Errorf("copied, but the original could only be partly removed: %w", err)
+	}
+	if err := replace(tmp, op.Target, op.Overwrite); err != nil {
+		return fmt.Errorf("%w (the moved copy is in %s)", err, tmp)
+	}
+	return nil
+}
+
+// replace renames tmp to target; with overwrite, an existing target is
+// moved aside first and only removed once tmp has taken its place
+func replace(tmp, target string, overwrite bool) error {
+	if !overwrite || !exists(target) {
+		// Rename would silently replace a file that appeared since Plan
+		if exists(target) {
+			return &os.LinkError{Op: "rename", Old: tmp, New: target, Err: fs.ErrExist}
+		}
+		return os.Rename(tmp, target)
+	}
+
+	old := tempName(target)
+	if err := os.Rename(target, old); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		os.Rename(old, target)
+		return err
+	}
+	return os.RemoveAll(old)
+}
+
+// tempName returns an unused hidden name next to path, for staging it
+func tempName(path string) string {
+	dir, base := filepath.Split(path)
+	for i := 0; ; i++ {
+		candidate := filepath.Join(dir, fmt.Sprintf(".%s.fcf-%d-%d", base, os.Getpid(), i))
+		if !exists(candidate) {
+			return candidate
+		}
+	}
+}
+
+// copyPath copies src to dst, preserving permissions and modification times
+func copyPath(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(link, dst)
+	case info.IsDir():
+		return copyDir(src, dst, info)
+	default:
+		return copyFile(src, dst, info)
+	}
+}
+
+// copyDir copies a directory tree; its times are set after its contents
+func copyDir(src, dst string, info os.FileInfo) error {
+	if err := os.Mkdir(dst, info.Mode().Perm()|0700); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := copyPath(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Chmod(dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, time.Time{}, info.ModTime())
+}
+
+// copyFile copies a regular file's contents, mode and modification time
+func copyFile(src, dst string, info os.FileInfo) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	// The umask may have masked bits at creation
+	if err := os.Chmod(dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, time.Time{}, info.ModTime())
+}
+
+// topLevel drops paths that lie inside another path of the list, so a
+// selected directory is handled once with its contents
+// Each path's parents are looked up among the selected directories; sorting
+// would not put a directory right before its contents, as "a/b c" sorts
+// between "a/b" and "a/b/d". Only directories are kept in the lookup, so a
+// huge list of files is read from the store without being copied
+func topLevel(paths *store.Paths) []string {
+	selected := map[string]bool{} // selected directories, true once kept
+	for i := 0; i < paths.Len(); i++ {
+		p := paths.At(i)
+		if info, err := os.Lstat(p); err == nil && info.IsDir() {
+			selected[filepath.Clean(p)] = false
+		}
+	}
+
+	var kept []string
+	for i := 0; i < paths.Len(); i++ {
+		p := paths.At(i)
+		clean := filepath.Clean(p)
+		if hasSelectedParent(clean, selected) {
+			continue
+		}
+		if done, ok := selected[clean]; ok {
+			if done {
+				continue
+			}
+			selected[clean] = true
+		}
+		kept = append(kept, p)
+	}
+	return kept
+}
+
+// hasSelectedParent reports whether a directory above path is in selected
+func hasSelectedParent(path string, selected map[string]bool) bool {
+	for {
+		parent := filepath.Dir(path)
+		if parent == path {
+			return false
+		}
+		if _, ok := selected[parent]; ok {
+			return true
+		}
+		path = parent
+	}
+}
+
+// isInside reports whether path is dir itself or below it
+func isInside(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// uniqueName returns "name (1).ext", "name (2).ext", ... for a taken target
+func uniqueName(target string, planned map[string]bool) string {
+	dir := filepath.Dir(target)
+	base := filepath.Base(target)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	for i := 1; ; i++ {
+		candidate := filepath.Join(dir, fmt.Sprintf("%s (%d)%s", stem, i, ext))
+		if !exists(candidate) && !planned[candidate] {
+			return candidate
+		}
+	}
+}
+
+// isEmptyDir reports whether dir is a directory with no entries
+func isEmptyDir(dir string) bool {
+	f, err := os.Open(dir)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	_, err = f.Readdirnames(1)
+	return err == io.EOF
+}
+
+// exists reports whether anything, including a dangling symlink, is at path
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// sameFile reports whether a and b are the same file on disk
+func sameFile(a, b string) bool {
+	ai, err := os.Lstat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Lstat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ai, bi)
+}
+
+// unwrapPathError drops the operation and path already shown next to the error
+func unwrapPathError(err error) error {
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	var linkErr *os.LinkError
+	if errors.As(err, &linkErr) {
+		return linkErr.Err
+	}
+	return err
+}
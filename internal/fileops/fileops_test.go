@@ -0,0 +1,138 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ReggieAlbiosA/fcf/internal/store"
+)
+
+func TestTopLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		dirs  []string // created as directories, everything else as files
+		paths []string
+		want  []string
+	}{
+		{
+			name:  "sibling sorting between a directory and its child",
+			dirs:  []string{"a/b", "a/b c", "a/b/d"},
+			paths: []string{"a/b", "a/b c", "a/b/d"},
+			want:  []string{"a/b", "a/b c"},
+		},
+		{
+			name:  "siblings with '-' and '.' before a grandchild",
+			dirs:  []string{"a/b/d"},
+			paths: []string{"a/b/d/e", "a/b-x", "a/b.txt", "a/b"},
+			want:  []string{"a/b-x", "a/b.txt", "a/b"},
+		},
+		{
+			name:  "a name that only shares a prefix is kept",
+			dirs:  []string{"a/b", "a/bc"},
+			paths: []string{"a/b", "a/bc"},
+			want:  []string{"a/b", "a/bc"},
+		},
+		{
+			name:  "results order and first duplicate directory kept",
+			dirs:  []string{"a", "z"},
+			paths: []string{"z", "a", "z", "a/x"},
+			want:  []string{"z", "a"},
+		},
+		{
+			name:  "a selected file is no parent",
+			dirs:  []string{"a"},
+			paths: []string{"a/f", "a/f/g", "a.txt"},
+			want:  []string{"a/f", "a/f/g", "a.txt"},
+		},
+	}
+	for _, tt := range tests {
+		root := t.TempDir()
+		for _, dir := range tt.dirs {
+			if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		paths := under(root, tt.paths)
+		for _, p := range paths {
+			if _, err := os.Lstat(p); err != nil && os.MkdirAll(filepath.Dir(p), 0o755) == nil {
+				os.WriteFile(p, nil, 0o644)
+			}
+		}
+
+		if got := topLevel(store.FromSlice(paths)); !reflect.DeepEqual(got, under(root, tt.want)) {
+			t.Errorf("%s: topLevel(%q) = %q, want %q", tt.name, tt.paths, got, under(root, tt.want))
+		}
+	}
+}
+
+// TestCopyMoveSiblingLayout runs each action on "a/b", "a/b c" and "a/b/d",
+// where "a/b/d" must only be handled as part of "a/b"
+func TestCopyMoveSiblingLayout(t *testing.T) {
+	for _, action := range []Action{Copy, Move, Delete} {
+		root := t.TempDir()
+		src := filepath.Join(root, "a")
+		dest := filepath.Join(root, "dest")
+		for _, dir := range []string{"b", "b c", "b/d"} {
+			if err := os.MkdirAll(filepath.Join(src, dir), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.MkdirAll(dest, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(src, "b", "d", "file"))
+		writeFile(t, filepath.Join(src, "b c", "file"))
+
+		paths := []string{filepath.Join(src, "b"), filepath.Join(src, "b c"), filepath.Join(src, "b", "d")}
+		ops := Plan(action, store.FromSlice(paths), dest, ConflictSkip)
+		if len(ops) != 2 {
+			t.Fatalf("%s: planned %d operations, want 2: %+v", action, len(ops), ops)
+		}
+		for _, r := range Execute(action, ops) {
+			if r.Err != nil {
+				t.Errorf("%s %s: %v", action, r.Op.Source, r.Err)
+			}
+		}
+
+		entries, err := os.ReadDir(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		want := []string{"b", "b c"}
+		if action == Delete {
+			want = nil
+		}
+		if !reflect.DeepEqual(names, want) {
+			t.Errorf("%s: destination holds %q, want %q", action, names, want)
+		}
+		if action != Delete && !exists(filepath.Join(dest, "b", "d", "file")) {
+			t.Errorf("%s: b/d/file missing from the destination", action)
+		}
+		if moved := !exists(filepath.Join(src, "b")); moved != (action != Copy) {
+			t.Errorf("%s: source a/b removed = %v", action, moved)
+		}
+	}
+}
+
+// under returns slash-separated test paths as paths below root
+func under(root string, paths []string) []string {
+	out := make([]string, len(paths))
+	for i, p := range paths {
+		out[i] = filepath.Join(root, filepath.FromSlash(p))
+	}
+	return out
+}
+
+// writeFile creates a small file at path
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,346 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Filter names accepted by --git
+const (
+	FilterTracked   = "tracked"
+	FilterModified  = "modified"
+	FilterUntracked = "untracked"
+	FilterStaged    = "staged"
+	FilterIgnored   = "ignored"
+)
+
+// Filters lists every valid --git value
+var Filters = []string{FilterTracked, FilterModified, FilterUntracked, FilterStaged, FilterIgnored}
+
+// IsValidFilter reports whether name is a known --git value
+func IsValidFilter(name string) bool {
+	for _, f := range Filters {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Available checks if git is available in PATH
+func Available() bool {
+	_, err := exec.LookPath("git")
+	return err == nil
+}
+
+// pathSet holds repository-relative paths and every directory above them
+type pathSet struct {
+	files map[string]string // path -> short status code
+	dirs  map[string]bool   // directories containing at least one path
+	trees []string          // collapsed directories ("dir/") covering everything below
+}
+
+func newPathSet() *pathSet {
+	return &pathSet{files: map[string]string{}, dirs: map[string]bool{}}
+}
+
+// add records a path reported by git
+func (s *pathSet) add(rel, code string) {
+	if strings.HasSuffix(rel, "/") {
+		s.trees = append(s.trees, rel)
+		rel = strings.TrimSuffix(rel, "/")
+	} else {
+		s.files[rel] = code
+	}
+	for dir := parentOf(rel); dir != ""; dir = parentOf(dir) {
+		s.dirs[dir] = true
+	}
+}
+
+// contains reports whether rel is in the set, holds a path from it, or lies in a collapsed directory
+func (s *pathSet) contains(rel string, isDir bool) bool {
+	if _, ok := s.files[rel]; ok {
+		return true
+	}
+	if isDir && s.dirs[rel] {
+		return true
+	}
+	return s.inTree(rel) != ""
+}
+
+// inTree returns the collapsed directory holding rel, if any
+func (s *pathSet) inTree(rel string) string {
+	for _, t := range s.trees {
+		if rel+"/" == t || strings.HasPrefix(rel, t) {
+			return t
+		}
+	}
+	return ""
+}
+
+// repoState is the git state of one working tree, loaded on first use
+type repoState struct {
+	root      string
+	tracked   *pathSet
+	modified  *pathSet
+	staged    *pathSet
+	untracked *pathSet
+	ignored   *pathSet
+	err       error
+}
+
+var (
+	mu    sync.Mutex
+	roots = map[string]string{}     // directory -> working tree root ("" if none)
+	repos = map[string]*repoState{} // working tree root -> state
+)
+
+// Reset forgets cached repository state so the next search sees fresh changes
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	roots = map[string]string{}
+	repos = map[string]*repoState{}
+}
+
+// Detect returns the working tree root containing path
+// Nested repositories, submodules and worktrees (a .git file) are found by
+// walking up from path to the closest .git entry
+func Detect(path string) (string, bool) {
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		path = filepath.Dir(path)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	root := findRoot(path)
+	return root, root != ""
+}
+
+// findRoot looks up the closest working tree root at or above dir; callers hold mu
+func findRoot(dir string) string {
+	var visited []string
+	root := ""
+	for {
+		if cached, ok := roots[dir]; ok {
+			root = cached
+			break
+		}
+		visited = append(visited, dir)
+		if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+			root = dir
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	for _, d := range visited {
+		roots[d] = root
+	}
+	return root
+}
+
+// lookup returns the repository state and repository-relative path for path
+// The repository is the one holding path's parent, so a nested repository's
+// root directory is judged by the repository around it
+func lookup(path string) (*repoState, string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	root := findRoot(filepath.Dir(path))
+	if root == "" {
+		return nil, ""
+	}
+
+	state, ok := repos[root]
+	if !ok {
+		state = loadState(root)
+		repos[root] = state
+	}
+	if state.err != nil {
+		return nil, ""
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return nil, ""
+	}
+	return state, filepath.ToSlash(rel)
+}
+
+// Match reports whether path satisfies the --git filter
+// Directories match when they hold at least one matching path
+func Match(path, filter string, isDir bool) bool {
+	state, rel := lookup(path)
+	if state == nil {
+		return false
+	}
+
+	switch filter {
+	case FilterTracked:
+		return state.tracked.contains(rel, isDir)
+	case FilterModified:
+		return state.modified.contains(rel, isDir)
+	case FilterStaged:
+		return state.staged.contains(rel, isDir)
+	case FilterUntracked:
+		return state.untracked.contains(rel, isDir)
+	case FilterIgnored:
+		return state.ignored.contains(rel, isDir)
+	default:
+		return false
+	}
+}
+
+// Status returns the short status code for path: "M", "A", "D", "R", "??"
+// for untracked, "!!" for ignored, or "" when clean or outside a repository
+func Status(path string) string {
+	state, rel := lookup(path)
+	if state == nil {
+		return ""
+	}
+
+	if code, ok := state.modified.files[rel]; ok {
+		return code
+	}
+	if code, ok := state.staged.files[rel]; ok {
+		return code
+	}
+	if state.untracked.contains(rel, false) {
+		return "??"
+	}
+	if state.ignored.contains(rel, false) {
+		return "!!"
+	}
+	return ""
+}
+
+// loadState reads the state of the working tree at root using the git CLI
+func loadState(root string) *repoState {
+	state := &repoState{
+		root:      root,
+		tracked:   newPathSet(),
+		modified:  newPathSet(),
+		staged:    newPathSet(),
+		untracked: newPathSet(),
+		ignored:   newPathSet(),
+	}
+
+	tracked, err := run(root, "ls-files", "-z")
+	if err != nil {
+		state.err = err
+		return state
+	}
+	for _, rel := range splitNul(tracked) {
+		state.tracked.add(rel, "")
+	}
+
+	// Untracked and ignored directories are reported collapsed ("dir/")
+	untracked, err := run(root, "ls-files", "-z", "--others", "--exclude-standard", "--directory")
+	if err != nil {
+		state.err = err
+		return state
+	}
+	for _, rel := range splitNul(untracked) {
+		state.untracked.add(rel, "??")
+	}
+
+	ignored, err := run(root, "ls-files", "-z", "--others", "--ignored", "--exclude-standard", "--directory")
+	if err != nil {
+		state.err = err
+		return state
+	}
+	for _, rel := range splitNul(ignored) {
+		state.ignored.add(rel, "!!")
+	}
+
+	status, err := run(root, "status", "--porcelain=v1", "-z", "--untracked-files=no")
+	if err != nil {
+		state.err = err
+		return state
+	}
+	parseStatus(status, state)
+
+	return state
+}
+
+// parseStatus fills the modified and staged sets from `git status --porcelain -z`
+func parseStatus(out []byte, state *repoState) {
+	fields := splitNul(out)
+	for i := 0; i < len(fields); i++ {
+		entry := fields[i]
+		if len(entry) < 4 {
+			continue
+		}
+		x, y, rel := entry[0], entry[1], entry[3:]
+
+		// Renames and copies are followed by the original path
+		if x == 'R' || x == 'C' {
+			i++
+		}
+
+		code := statusCode(x, y)
+		if x != ' ' {
+			state.staged.add(rel, code)
+		}
+		if x == 'M' || x == 'T' || y != ' ' {
+			state.modified.add(rel, code)
+		}
+	}
+}
+
+// statusCode reduces a porcelain XY pair to the code shown in the status column
+func statusCode(x, y byte) string {
+	switch {
+	case x == 'A':
+		return "A"
+	case x == 'R' || x == 'C':
+		return "R"
+	case x == 'D' || y == 'D':
+		return "D"
+	case x == 'U' || y == 'U':
+		return "U"
+	default:
+		return "M"
+	}
+}
+
+// run executes a git command in dir and returns its output
+func run(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// splitNul splits NUL-separated git output
+func splitNul(out []byte) []string {
+	var fields []string
+	for _, f := range bytes.Split(out, []byte{0}) {
+		if len(f) > 0 {
+			fields = append(fields, string(f))
+		}
+	}
+	return fields
+}
+
+// parentOf returns the parent of a slash-separated relative path, or ""
+func parentOf(rel string) string {
+	if i := strings.LastIndex(rel, "/"); i >= 0 {
+		return rel[:i]
+	}
+	return ""
+}
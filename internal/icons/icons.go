@@ -0,0 +1,180 @@
+package icons
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ReggieAlbiosA/fcf/internal/kind"
+	"github.com/ReggieAlbiosA/fcf/internal/platform"
+)
+
+// Set is a family of icons chosen with --icons
+type Set string
+
+// Icon sets
+const (
+	Emoji Set = "emoji"
+	Nerd  Set = "nerd" // needs a Nerd Font in the terminal
+	ASCII Set = "ascii"
+	None  Set = "none"
+)
+
+// Sets lists the valid --icons values
+var Sets = []Set{Emoji, Nerd, ASCII, None}
+
+// IsValid reports whether name is an icon set
+func IsValid(name string) bool {
+	for _, s := range Sets {
+		if string(s) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// icon is one category's icon in each set
+type icon struct {
+	emoji, nerd, ascii string
+}
+
+// Icons by category; ASCII icons are three characters wide so paths line up
+var table = map[string]icon{
+	"dir":      {"📁", "\uf07b", "dir"},
+	"link":     {"🔗", "\uf0c1", "lnk"},
+	"exec":     {"⚡", "\uf489", "exe"},
+	"file":     {"📄", "\uf15b", "---"},
+	"text":     {"📄", "\uf15c", "txt"},
+	"image":    {"🎨", "\uf1c5", "img"},
+	"video":    {"🎬", "\uf1c8", "vid"},
+	"audio":    {"🎵", "\uf1c7", "aud"},
+	"archive":  {"📦", "\uf1c6", "arc"},
+	"pdf":      {"📕", "\uf1c1", "pdf"},
+	"script":   {"📜", "\ue795", "sh "},
+	"binary":   {"💾", "\uf471", "bin"},
+	"go":       {"🐹", "\ue627", "go "},
+	"rust":     {"🦀", "\ue7a8", "rs "},
+	"python":   {"🐍", "\ue606", "py "},
+	"ruby":     {"💎", "\ue739", "rb "},
+	"java":     {"☕", "\ue738", "jav"},
+	"js":       {"📝", "\ue74e", "js "},
+	"ts":       {"📝", "\ue628", "ts "},
+	"c":        {"📝", "\ue61e", "c  "},
+	"cpp":      {"📝", "\ue61d", "c++"},
+	"html":     {"🌐", "\ue736", "htm"},
+	"css":      {"💅", "\ue749", "css"},
+	"markdown": {"📝", "\ue609", "md "},
+	"config":   {"🔧", "\ue615", "cfg"},
+	"data":     {"📊", "\uf1c0", "dat"},
+	"docker":   {"🐳", "\uf308", "dkr"},
+	"make":     {"🔨", "\ue673", "mak"},
+	"git":      {"🌱", "\ue702", "git"},
+	"lock":     {"🔒", "\uf023", "lck"},
+	"license":  {"🧾", "\ue60a", "lic"},
+}
+
+// Categories of well-known file names, matched case-insensitively
+var names = map[string]string{
+	"dockerfile":         "docker",
+	"containerfile":      "docker",
+	"docker-compose.yml": "docker",
+	"compose.yaml":       "docker",
+	"makefile":           "make",
+	"gnumakefile":        "make",
+	"justfile":           "make",
+	"cmakelists.txt":     "make",
+	".gitignore":         "git",
+	".gitattributes":     "git",
+	".gitmodules":        "git",
+	"go.mod":             "go",
+	"go.sum":             "lock",
+	"cargo.toml":         "rust",
+	"cargo.lock":         "lock",
+	"package-lock.json":  "lock",
+	"yarn.lock":          "lock",
+	"license":            "license",
+	"license.md":         "license",
+	"license.txt":        "license",
+	"copying":            "license",
+	"readme":             "markdown",
+}
+
+// Categories by extension, without the dot and in lower case
+var extensions = map[string]string{
+	"go": "go", "rs": "rust", "py": "python", "rb": "ruby", "java": "java",
+	"js": "js", "mjs": "js", "cjs": "js", "jsx": "js",
+	"ts": "ts", "tsx": "ts",
+	"c": "c", "h": "c", "cc": "cpp", "cpp": "cpp", "cxx": "cpp", "hpp": "cpp",
+	"html": "html", "htm": "html", "css": "css", "scss": "css",
+	"md": "markdown", "markdown": "markdown",
+	"json": "config", "yaml": "config", "yml": "config", "toml": "config", "ini": "config", "conf": "config", "xml": "config",
+	"csv": "data", "tsv": "data", "sql": "data", "db": "data", "sqlite": "data",
+	"sh": "script", "bash": "script", "zsh": "script", "fish": "script", "ps1": "script",
+	"png": "image", "jpg": "image", "jpeg": "image", "gif": "image", "webp": "image", "svg": "image", "bmp": "image", "ico": "image",
+	"mp4": "video", "mkv": "video", "mov": "video", "avi": "video", "webm": "video",
+	"mp3": "audio", "flac": "audio", "wav": "audio", "ogg": "audio", "m4a": "audio",
+	"zip": "archive", "tar": "archive", "gz": "archive", "tgz": "archive", "xz": "archive", "bz2": "archive", "zst": "archive", "7z": "archive", "rar": "archive",
+	"pdf": "pdf", "txt": "text", "log": "text",
+	"lock": "lock",
+}
+
+// Categories for content detected by the kind package
+var kinds = map[kind.Kind]string{
+	kind.Image:   "image",
+	kind.Video:   "video",
+	kind.Audio:   "audio",
+	kind.Archive: "archive",
+	kind.PDF:     "pdf",
+	kind.Script:  "script",
+	kind.Binary:  "binary",
+	kind.ELF:     "binary",
+	kind.Text:    "text",
+}
+
+// For returns the icon for path in set, or "" for the none set
+// Regular files are matched by name, then extension, then by their content,
+// which is only read when the name says nothing
+func For(set Set, path string, info os.FileInfo) string {
+	if set == None {
+		return ""
+	}
+	return pick(set, table[category(path, info)])
+}
+
+// category names the table entry for path
+func category(path string, info os.FileInfo) string {
+	switch {
+	case info.IsDir():
+		return "dir"
+	case info.Mode()&os.ModeSymlink != 0:
+		return "link"
+	case platform.IsExecutable(path):
+		return "exec"
+	case !info.Mode().IsRegular():
+		return "file"
+	}
+
+	name := strings.ToLower(filepath.Base(path))
+	if c, ok := names[name]; ok {
+		return c
+	}
+	if c, ok := extensions[strings.TrimPrefix(filepath.Ext(name), ".")]; ok {
+		return c
+	}
+	if c, ok := kinds[kind.Detect(path)]; ok {
+		return c
+	}
+	return "file"
+}
+
+// pick returns the icon of set
+func pick(set Set, i icon) string {
+	switch set {
+	case Nerd:
+		return i.nerd
+	case ASCII:
+		return i.ascii
+	default:
+		return i.emoji
+	}
+}
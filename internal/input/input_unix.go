@@ -97,3 +97,12 @@ func StartKeyListener(keyChan chan<- string) (stop func()) {
 		}
 	}
 }
+
+// TerminalSize returns the width and height of the terminal attached to stdout
+func TerminalSize() (width, height int, err error) {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(ws.Col), int(ws.Row), nil
+}
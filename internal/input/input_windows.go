@@ -14,10 +14,12 @@ var (
 	procGetNumberOfConsoleInputEvents = kernel32.NewProc("GetNumberOfConsoleInputEvents")
 	procSetConsoleMode = kernel32.NewProc("SetConsoleMode")
 	procGetConsoleMode = kernel32.NewProc("GetConsoleMode")
+	procGetConsoleScreenBufferInfo = kernel32.NewProc("GetConsoleScreenBufferInfo")
 )
 
 const (
 	stdInputHandle = ^uintptr(0) - 10 + 1 // STD_INPUT_HANDLE = -10
+	stdOutputHandle = ^uintptr(0) - 11 + 1 // STD_OUTPUT_HANDLE = -11
 	enableEchoInput = 0x0004
 	enableLineInput = 0x0002
 	enableProcessedInput = 0x0001
@@ -39,6 +41,22 @@ type keyEventRecord struct {
 	ControlKeyState uint32
 }
 
+type coord struct {
+	X, Y int16
+}
+
+type smallRect struct {
+	Left, Top, Right, Bottom int16
+}
+
+type consoleScreenBufferInfo struct {
+	Size              coord
+	CursorPosition    coord
+	Attributes        uint16
+	Window            smallRect
+	MaximumWindowSize coord
+}
+
 func getStdHandle(handle uintptr) uintptr {
 	ret, _, _ := procGetStdHandle.Call(handle)
 	return ret
@@ -119,3 +137,15 @@ func StartKeyListener(keyChan chan<- string) (stop func()) {
 		procSetConsoleMode.Call(handle, uintptr(oldMode))
 	}
 }
+
+// TerminalSize returns the width and height of the console window
+func TerminalSize() (width, height int, err error) {
+	handle := getStdHandle(stdOutputHandle)
+
+	var info consoleScreenBufferInfo
+	ret, _, callErr := procGetConsoleScreenBufferInfo.Call(handle, uintptr(unsafe.Pointer(&info)))
+	if ret == 0 {
+		return 0, 0, callErr
+	}
+	return int(info.Window.Right-info.Window.Left) + 1, int(info.Window.Bottom-info.Window.Top) + 1, nil
+}
@@ -0,0 +1,47 @@
+package input
+
+// KeyCode identifies a key read by a Screen
+type KeyCode int
+
+// Keys a Screen reports; printable characters are KeyRune
+const (
+	KeyRune KeyCode = iota
+	KeyUp
+	KeyDown
+	KeyPageUp
+	KeyPageDown
+	KeyHome
+	KeyEnd
+	KeyEnter
+	KeyEscape
+	KeyBackspace
+	KeyClear // Ctrl+U
+)
+
+// Key is a key press, with the character typed for KeyRune
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+// Screen is the terminal taken over by a full-screen view: input is raw,
+// the alternate screen is shown so the scrollback is left as it was, and
+// line wrapping is off so long lines are cut at the edge
+type Screen struct {
+	Keys   <-chan Key      // key presses
+	Resize <-chan struct{} // the terminal changed size
+
+	close func()
+}
+
+// Close restores the terminal and the screen shown before OpenScreen
+func (s *Screen) Close() {
+	s.close()
+}
+
+// Escape sequences around a full-screen view: alternate screen, hidden
+// cursor and no line wrapping, and their reverse
+const (
+	enterScreen = "\033[?1049h\033[?25l\033[?7l"
+	leaveScreen = "\033[?7h\033[?25h\033[?1049l"
+)
@@ -0,0 +1,209 @@
+//go:build unix
+
+package input
+
+import (
+	"os"
+	"os/signal"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/sys/unix"
+)
+
+// IsInteractive reports whether both stdin and stdout are terminals, which
+// a full-screen view needs
+func IsInteractive() bool {
+	_, inErr := unix.IoctlGetTermios(int(os.Stdin.Fd()), ioctlReadTermios)
+	_, outErr := unix.IoctlGetTermios(int(os.Stdout.Fd()), ioctlReadTermios)
+	return inErr == nil && outErr == nil
+}
+
+// OpenScreen switches the terminal to raw input and the alternate screen
+// Ctrl+C arrives as KeyEscape instead of a signal, so the terminal is
+// always restored by Close
+func OpenScreen() (*Screen, error) {
+	fd := int(os.Stdin.Fd())
+	oldTermios, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := *oldTermios
+	raw.Lflag &^= unix.ICANON | unix.ECHO | unix.ISIG | unix.IEXTEN
+	raw.Iflag &^= unix.IXON | unix.ICRNL
+	// Reads wait at most 0.1s, so the reader notices Close
+	raw.Cc[unix.VMIN] = 0
+	raw.Cc[unix.VTIME] = 1
+	if err := unix.IoctlSetTermios(fd, ioctlWriteTermios, &raw); err != nil {
+		return nil, err
+	}
+	os.Stdout.WriteString(enterScreen)
+
+	keys := make(chan Key, 16)
+	resize := make(chan struct{}, 1)
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, unix.SIGWINCH)
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	// Key reader; it stops between reads so no input meant for the next
+	// prompt is consumed after Close
+	go func() {
+		defer close(stopped)
+		buf := make([]byte, 64)
+		var pending []byte
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			n, _ := os.Stdin.Read(buf)
+			var decoded []Key
+			if n == 0 {
+				// Nothing followed within the read timeout: what is left is
+				// the Escape key on its own, or a sequence cut short
+				decoded, pending = flushKeys(pending), nil
+			} else {
+				decoded, pending = decodeKeys(append(pending, buf[:n]...))
+			}
+			for _, k := range decoded {
+				select {
+				case keys <- k:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	// Resize notifications, coalesced while the view is redrawing
+	go func() {
+		for {
+			select {
+			case <-winch:
+				select {
+				case resize <- struct{}{}:
+				default:
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return &Screen{
+		Keys:   keys,
+		Resize: resize,
+		close: func() {
+			signal.Stop(winch)
+			close(done)
+			<-stopped
+			os.Stdout.WriteString(leaveScreen)
+			unix.IoctlSetTermios(fd, ioctlWriteTermios, oldTermios)
+		},
+	}, nil
+}
+
+// decodeKeys turns terminal input into keys; an incomplete UTF-8
+// character or escape sequence at the end is returned to be completed by
+// the next read, since a key's bytes can arrive in separate reads
+func decodeKeys(b []byte) (keys []Key, rest []byte) {
+	for len(b) > 0 {
+		switch c := b[0]; c {
+		case 0x1b:
+			k, n := decodeEscape(b)
+			if n == 0 {
+				return keys, b
+			}
+			if k.Code != KeyRune {
+				keys = append(keys, k)
+			}
+			b = b[n:]
+			continue
+		case '\r', '\n':
+			keys = append(keys, Key{Code: KeyEnter})
+		case 0x7f, 0x08:
+			keys = append(keys, Key{Code: KeyBackspace})
+		case 0x03: // Ctrl+C
+			keys = append(keys, Key{Code: KeyEscape})
+		case 0x15: // Ctrl+U
+			keys = append(keys, Key{Code: KeyClear})
+		case 0x10: // Ctrl+P
+			keys = append(keys, Key{Code: KeyUp})
+		case 0x0e: // Ctrl+N
+			keys = append(keys, Key{Code: KeyDown})
+		default:
+			if !utf8.FullRune(b) {
+				return keys, b
+			}
+			r, n := utf8.DecodeRune(b)
+			if r != utf8.RuneError && unicode.IsPrint(r) {
+				keys = append(keys, Key{Code: KeyRune, Rune: r})
+			}
+			b = b[n:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys, nil
+}
+
+// flushKeys returns the keys in input left over when no more arrived: an
+// ESC on its own is the Escape key, and anything else is incomplete
+func flushKeys(rest []byte) []Key {
+	if len(rest) == 1 && rest[0] == 0x1b {
+		return []Key{{Code: KeyEscape}}
+	}
+	return nil
+}
+
+// decodeEscape reads the escape sequence at the start of b and returns its
+// key and length, or a length of 0 when the sequence may not be complete
+// yet. ESC followed by anything but a sequence is the Escape key, and
+// sequences for keys a Screen does not report come back as KeyRune so they
+// can be dropped
+func decodeEscape(b []byte) (Key, int) {
+	if len(b) == 1 {
+		return Key{}, 0
+	}
+	if b[1] != '[' && b[1] != 'O' {
+		return Key{Code: KeyEscape}, 1
+	}
+
+	// ESC [ parameters final, e.g. ESC [ A or ESC [ 5 ~
+	end := 2
+	for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+		end++
+	}
+	if end == len(b) {
+		return Key{}, 0
+	}
+	params, final := string(b[2:end]), b[end]
+	n := end + 1
+
+	switch final {
+	case 'A':
+		return Key{Code: KeyUp}, n
+	case 'B':
+		return Key{Code: KeyDown}, n
+	case 'H':
+		return Key{Code: KeyHome}, n
+	case 'F':
+		return Key{Code: KeyEnd}, n
+	case '~':
+		switch params {
+		case "5":
+			return Key{Code: KeyPageUp}, n
+		case "6":
+			return Key{Code: KeyPageDown}, n
+		case "1", "7":
+			return Key{Code: KeyHome}, n
+		case "4", "8":
+			return Key{Code: KeyEnd}, n
+		}
+	}
+	return Key{}, n
+}
@@ -0,0 +1,51 @@
+//go:build unix
+
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestDecodeKeysAcrossReads feeds input split into reads and checks that
+// keys split between them come out whole
+func TestDecodeKeysAcrossReads(t *testing.T) {
+	tests := []struct {
+		name  string
+		reads []string
+		want  []Key
+	}{
+		{"arrow in one read", []string{"\x1b[A"}, []Key{{Code: KeyUp}}},
+		{"arrow after its ESC", []string{"\x1b", "[B"}, []Key{{Code: KeyDown}}},
+		{"arrow split in three", []string{"\x1b", "[", "A"}, []Key{{Code: KeyUp}}},
+		{"page down split in its parameter", []string{"\x1b[6", "~"}, []Key{{Code: KeyPageDown}}},
+		{"SS3 home split", []string{"\x1bO", "H"}, []Key{{Code: KeyHome}}},
+		{"keys around a split sequence", []string{"a\x1b[", "Bb"}, []Key{{Code: KeyRune, Rune: 'a'}, {Code: KeyDown}, {Code: KeyRune, Rune: 'b'}}},
+		{"lone ESC then a timeout", []string{"\x1b", ""}, []Key{{Code: KeyEscape}}},
+		{"ESC then a letter", []string{"\x1bx"}, []Key{{Code: KeyEscape}, {Code: KeyRune, Rune: 'x'}}},
+		{"two ESCs then a timeout", []string{"\x1b\x1b", ""}, []Key{{Code: KeyEscape}, {Code: KeyEscape}}},
+		{"cut-off sequence is dropped", []string{"\x1b[5", "", "j"}, []Key{{Code: KeyRune, Rune: 'j'}}},
+		{"unreported key is dropped", []string{"\x1b[2~q"}, []Key{{Code: KeyRune, Rune: 'q'}}},
+		{"rune split across reads", []string{"\xc3", "\xa9"}, []Key{{Code: KeyRune, Rune: 'é'}}},
+		{"controls", []string{"\r\x7f\x15\x03"}, []Key{{Code: KeyEnter}, {Code: KeyBackspace}, {Code: KeyClear}, {Code: KeyEscape}}},
+	}
+	for _, tt := range tests {
+		var got, decoded []Key
+		var pending []byte
+		for _, read := range tt.reads {
+			// An empty read is the read timeout passing with no input
+			if read == "" {
+				decoded, pending = flushKeys(pending), nil
+			} else {
+				decoded, pending = decodeKeys(append(pending, read...))
+			}
+			got = append(got, decoded...)
+		}
+		if len(pending) > 0 {
+			t.Errorf("%s: %q left undecoded", tt.name, pending)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: keys %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
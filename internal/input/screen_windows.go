@@ -0,0 +1,166 @@
+//go:build windows
+
+package input
+
+import (
+	"os"
+	"time"
+	"unicode"
+	"unsafe"
+)
+
+const (
+	enableVirtualTerminalProcessing = 0x0004
+
+	vkBack   = 0x08
+	vkReturn = 0x0D
+	vkEscape = 0x1B
+	vkPrior  = 0x21 // Page Up
+	vkNext   = 0x22 // Page Down
+	vkEnd    = 0x23
+	vkHome   = 0x24
+	vkUp     = 0x26
+	vkDown   = 0x28
+
+	leftCtrlPressed  = 0x0008
+	rightCtrlPressed = 0x0004
+)
+
+// IsInteractive reports whether both stdin and stdout are consoles, which
+// a full-screen view needs
+func IsInteractive() bool {
+	var mode uint32
+	inOK, _, _ := procGetConsoleMode.Call(getStdHandle(stdInputHandle), uintptr(unsafe.Pointer(&mode)))
+	outOK, _, _ := procGetConsoleMode.Call(getStdHandle(stdOutputHandle), uintptr(unsafe.Pointer(&mode)))
+	return inOK != 0 && outOK != 0
+}
+
+// OpenScreen switches the console to raw input and the alternate screen
+// Windows has no SIGWINCH, so the window size is polled for Resize
+func OpenScreen() (*Screen, error) {
+	in := getStdHandle(stdInputHandle)
+	out := getStdHandle(stdOutputHandle)
+
+	var oldIn, oldOut uint32
+	if ret, _, err := procGetConsoleMode.Call(in, uintptr(unsafe.Pointer(&oldIn))); ret == 0 {
+		return nil, err
+	}
+	if ret, _, err := procGetConsoleMode.Call(out, uintptr(unsafe.Pointer(&oldOut))); ret == 0 {
+		return nil, err
+	}
+	// Ctrl+C arrives as a key instead of ending the program
+	procSetConsoleMode.Call(in, uintptr(oldIn&^(enableLineInput|enableEchoInput|enableProcessedInput)))
+	if ret, _, err := procSetConsoleMode.Call(out, uintptr(oldOut|enableVirtualTerminalProcessing)); ret == 0 {
+		procSetConsoleMode.Call(in, uintptr(oldIn))
+		return nil, err
+	}
+	os.Stdout.WriteString(enterScreen)
+
+	keys := make(chan Key, 16)
+	resize := make(chan struct{}, 1)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		width, height, _ := TerminalSize()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			if w, h, err := TerminalSize(); err == nil && (w != width || h != height) {
+				width, height = w, h
+				select {
+				case resize <- struct{}{}:
+				default:
+				}
+			}
+
+			k, ok := readConsoleKey(in)
+			if !ok {
+				time.Sleep(20 * time.Millisecond)
+				continue
+			}
+			select {
+			case keys <- k:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return &Screen{
+		Keys:   keys,
+		Resize: resize,
+		close: func() {
+			close(done)
+			<-stopped
+			os.Stdout.WriteString(leaveScreen)
+			procSetConsoleMode.Call(out, uintptr(oldOut))
+			procSetConsoleMode.Call(in, uintptr(oldIn))
+		},
+	}, nil
+}
+
+// readConsoleKey reads one pending console event without blocking and
+// returns it as a key; ok is false when nothing was pressed
+func readConsoleKey(handle uintptr) (k Key, ok bool) {
+	var numEvents uint32
+	ret, _, _ := procGetNumberOfConsoleInputEvents.Call(handle, uintptr(unsafe.Pointer(&numEvents)))
+	if ret == 0 || numEvents == 0 {
+		return Key{}, false
+	}
+
+	var ir inputRecord
+	var numRead uint32
+	ret, _, _ = procReadConsoleInput.Call(handle, uintptr(unsafe.Pointer(&ir)), 1, uintptr(unsafe.Pointer(&numRead)))
+	if ret == 0 || numRead == 0 || ir.EventType != keyEvent {
+		return Key{}, false
+	}
+	ev := (*keyEventRecord)(unsafe.Pointer(&ir.Event[0]))
+	if ev.KeyDown == 0 {
+		return Key{}, false
+	}
+
+	switch ev.VirtualKeyCode {
+	case vkUp:
+		return Key{Code: KeyUp}, true
+	case vkDown:
+		return Key{Code: KeyDown}, true
+	case vkPrior:
+		return Key{Code: KeyPageUp}, true
+	case vkNext:
+		return Key{Code: KeyPageDown}, true
+	case vkHome:
+		return Key{Code: KeyHome}, true
+	case vkEnd:
+		return Key{Code: KeyEnd}, true
+	case vkReturn:
+		return Key{Code: KeyEnter}, true
+	case vkEscape:
+		return Key{Code: KeyEscape}, true
+	case vkBack:
+		return Key{Code: KeyBackspace}, true
+	}
+
+	if ev.ControlKeyState&(leftCtrlPressed|rightCtrlPressed) != 0 {
+		switch ev.Char {
+		case 0x03: // Ctrl+C
+			return Key{Code: KeyEscape}, true
+		case 0x15: // Ctrl+U
+			return Key{Code: KeyClear}, true
+		case 0x10: // Ctrl+P
+			return Key{Code: KeyUp}, true
+		case 0x0e: // Ctrl+N
+			return Key{Code: KeyDown}, true
+		}
+		return Key{}, false
+	}
+	if r := rune(ev.Char); r != 0 && unicode.IsPrint(r) {
+		return Key{Code: KeyRune, Rune: r}, true
+	}
+	return Key{}, false
+}
@@ -24,15 +24,15 @@ func RunInstall() {
 	shellOnly := fs.Bool("shell-only", false, "Only install shell integration (skip binary installation)")
 	fs.Parse(os.Args[2:])
 
-	fmt.Println(ui.Colors.Bold(ui.Colors.Cyan("╔════════════════════════════════════════╗")))
-	fmt.Println(ui.Colors.Bold(ui.Colors.Cyan("║")) + "   " + ui.Colors.Bold("fcf") + " - Installation                  " + ui.Colors.Bold(ui.Colors.Cyan("║")))
-	fmt.Println(ui.Colors.Bold(ui.Colors.Cyan("╚════════════════════════════════════════╝")))
+	fmt.Println(ui.Colors.Header("╔════════════════════════════════════════╗"))
+	fmt.Println(ui.Colors.Header("║") + "   " + ui.Colors.Bold("fcf") + " - Installation                  " + ui.Colors.Header("║"))
+	fmt.Println(ui.Colors.Header("╚════════════════════════════════════════╝"))
 	fmt.Println()
 
 	// Shell-only mode: skip binary installation and privilege check
 	if *shellOnly {
 		if *noShell {
-			fmt.Println(ui.Colors.Red("Error: --shell-only and --no-shell cannot be used together"))
+			fmt.Println(ui.Colors.Error("Error: --shell-only and --no-shell cannot be used together"))
 			os.Exit(1)
 		}
 		fmt.Printf("%s %s\n", ui.Colors.Blue("Mode:"), ui.Colors.Cyan("Shell integration only"))
@@ -44,7 +44,7 @@ func RunInstall() {
 
 	// Check for elevated privileges
 	if !isElevated() {
-		fmt.Println(ui.Colors.Red("Error: Installation requires elevated privileges."))
+		fmt.Println(ui.Colors.Error("Error: Installation requires elevated privileges."))
 		fmt.Println()
 		if runtime.GOOS == "windows" {
 			fmt.Println("Please run this command as Administrator:")
@@ -69,28 +69,28 @@ func RunInstall() {
 	// Get current executable path
 	execPath, err := os.Executable()
 	if err != nil {
-		fmt.Printf("%s %s\n", ui.Colors.Red("Error:"), "Could not determine executable path")
+		fmt.Printf("%s %s\n", ui.Colors.Error("Error:"), "Could not determine executable path")
 		os.Exit(1)
 	}
 
 	// Ensure install directory exists
 	if err := ensureInstallDir(); err != nil {
-		fmt.Printf("%s %s\n", ui.Colors.Red("Error:"), err.Error())
+		fmt.Printf("%s %s\n", ui.Colors.Error("Error:"), err.Error())
 		os.Exit(1)
 	}
 
 	// Copy binary to install location
 	fmt.Printf("%s", ui.Colors.Yellow("Installing fcf... "))
 	if err := copyFile(execPath, installPath); err != nil {
-		fmt.Println(ui.Colors.Red("FAILED"))
-		fmt.Printf("%s %s\n", ui.Colors.Red("Error:"), err.Error())
+		fmt.Println(ui.Colors.Error("FAILED"))
+		fmt.Printf("%s %s\n", ui.Colors.Error("Error:"), err.Error())
 		os.Exit(1)
 	}
 
 	// Make executable (Unix only, no-op on Windows)
 	if err := makeExecutable(installPath); err != nil {
-		fmt.Println(ui.Colors.Red("FAILED"))
-		fmt.Printf("%s %s\n", ui.Colors.Red("Error:"), err.Error())
+		fmt.Println(ui.Colors.Error("FAILED"))
+		fmt.Printf("%s %s\n", ui.Colors.Error("Error:"), err.Error())
 		os.Exit(1)
 	}
 
@@ -176,7 +176,7 @@ func installShellIntegration(shellOverride string) {
 		case "fish":
 			shellType = shell.ShellFish
 		default:
-			fmt.Printf("%s Unknown shell: %s\n", ui.Colors.Red("Error:"), shellOverride)
+			fmt.Printf("%s Unknown shell: %s\n", ui.Colors.Error("Error:"), shellOverride)
 			return
 		}
 		configPath := shell.GetShellConfigPath(homeDir, shellType)
@@ -211,7 +211,7 @@ func installShellIntegration(shellOverride string) {
 
 		// Add shell integration
 		if err := shell.AddShellIntegration(s.ConfigPath, s.Type); err != nil {
-			fmt.Printf("%s %s\n", ui.Colors.Red("FAILED"), err.Error())
+			fmt.Printf("%s %s\n", ui.Colors.Error("FAILED"), err.Error())
 			continue
 		}
 
@@ -15,14 +15,14 @@ import (
 func RunUninstall() {
 	ui.InitColors()
 
-	fmt.Println(ui.Colors.Bold(ui.Colors.Cyan("╔════════════════════════════════════════╗")))
-	fmt.Println(ui.Colors.Bold(ui.Colors.Cyan("║")) + "   " + ui.Colors.Bold("fcf") + " - Uninstallation                " + ui.Colors.Bold(ui.Colors.Cyan("║")))
-	fmt.Println(ui.Colors.Bold(ui.Colors.Cyan("╚════════════════════════════════════════╝")))
+	fmt.Println(ui.Colors.Header("╔════════════════════════════════════════╗"))
+	fmt.Println(ui.Colors.Header("║") + "   " + ui.Colors.Bold("fcf") + " - Uninstallation                " + ui.Colors.Header("║"))
+	fmt.Println(ui.Colors.Header("╚════════════════════════════════════════╝"))
 	fmt.Println()
 
 	// Check for elevated privileges
 	if !isElevated() {
-		fmt.Println(ui.Colors.Red("Error: Uninstallation requires elevated privileges."))
+		fmt.Println(ui.Colors.Error("Error: Uninstallation requires elevated privileges."))
 		fmt.Println()
 		if runtime.GOOS == "windows" {
 			fmt.Println("Please run this command as Administrator:")
@@ -39,7 +39,7 @@ func RunUninstall() {
 	fmt.Printf("  - Binary at %s\n", ui.Colors.Cyan(getInstallPath()))
 	fmt.Println("  - Shell integration from your shell config files")
 	fmt.Println()
-	fmt.Print(ui.Colors.Bold("Are you sure you want to uninstall fcf? [y/N] "))
+	fmt.Print(ui.Colors.Prompt("Are you sure you want to uninstall fcf? [y/N] "))
 
 	reader := bufio.NewReader(os.Stdin)
 	response, _ := reader.ReadString('\n')
@@ -63,8 +63,8 @@ func RunUninstall() {
 		if os.IsNotExist(err) {
 			fmt.Println(ui.Colors.Yellow("not found (already removed)"))
 		} else {
-			fmt.Println(ui.Colors.Red("FAILED"))
-			fmt.Printf("%s %s\n", ui.Colors.Red("Error:"), err.Error())
+			fmt.Println(ui.Colors.Error("FAILED"))
+			fmt.Printf("%s %s\n", ui.Colors.Error("Error:"), err.Error())
 		}
 	} else {
 		fmt.Println(ui.Colors.Green("OK"))
@@ -111,7 +111,7 @@ func removeShellIntegration() {
 		}
 
 		if err := shell.RemoveShellIntegration(s.ConfigPath); err != nil {
-			fmt.Printf("%s %s\n", ui.Colors.Red("FAILED"), err.Error())
+			fmt.Printf("%s %s\n", ui.Colors.Error("FAILED"), err.Error())
 			continue
 		}
 
@@ -38,8 +38,8 @@ func RunUpdate(currentVersion string) {
 	fmt.Printf("%s", ui.Colors.Yellow("Checking for updates... "))
 	latestVersion, err := getLatestVersion()
 	if err != nil {
-		fmt.Println(ui.Colors.Red("FAILED"))
-		fmt.Printf("%s %s\n", ui.Colors.Red("Error:"), err.Error())
+		fmt.Println(ui.Colors.Error("FAILED"))
+		fmt.Printf("%s %s\n", ui.Colors.Error("Error:"), err.Error())
 		os.Exit(1)
 	}
 	fmt.Println(ui.Colors.Green("OK"))
@@ -58,7 +58,7 @@ func RunUpdate(currentVersion string) {
 
 	// Check for elevated privileges
 	if !isElevated() {
-		fmt.Println(ui.Colors.Red("Error: Update requires elevated privileges."))
+		fmt.Println(ui.Colors.Error("Error: Update requires elevated privileges."))
 		fmt.Println()
 		if runtime.GOOS == "windows" {
 			fmt.Println("Please run this command as Administrator:")
@@ -72,7 +72,7 @@ func RunUpdate(currentVersion string) {
 
 	// Download and install update
 	if err := downloadAndInstall(latestVersion); err != nil {
-		fmt.Printf("%s %s\n", ui.Colors.Red("Error:"), err.Error())
+		fmt.Printf("%s %s\n", ui.Colors.Error("Error:"), err.Error())
 		os.Exit(1)
 	}
 
@@ -0,0 +1,189 @@
+package kind
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kind is the type of a file's content, detected from its first bytes
+type Kind string
+
+const (
+	Unknown Kind = ""
+	Image   Kind = "image"
+	Video   Kind = "video"
+	Audio   Kind = "audio"
+	Archive Kind = "archive"
+	PDF     Kind = "pdf"
+	Text    Kind = "text"
+	Binary  Kind = "binary"
+	ELF     Kind = "elf"
+	Script  Kind = "script"
+)
+
+// Kinds lists every valid --kind value
+var Kinds = []Kind{Image, Video, Audio, Archive, PDF, Text, Binary, ELF, Script}
+
+// IsValid reports whether name is a known --kind value
+func IsValid(name string) bool {
+	for _, k := range Kinds {
+		if string(k) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sniffLen is how much of a file is read; tar's magic sits at offset 257
+const sniffLen = 512
+
+// signature is a magic byte sequence at a fixed offset
+type signature struct {
+	offset int
+	magic  string
+	kind   Kind
+}
+
+// signatures is checked in order, before falling back to content sniffing
+// Each magic is distinctive enough to win over the text check
+var signatures = []signature{
+	{0, "\x7fELF", ELF},
+	{0, "%PDF-", PDF},
+
+	{0, "\x89PNG\r\n\x1a\n", Image},
+	{0, "\xff\xd8\xff", Image},
+	{0, "GIF87a", Image},
+	{0, "GIF89a", Image},
+	{0, "II*\x00", Image},
+	{0, "MM\x00*", Image},
+	{0, "\x00\x00\x01\x00", Image},
+	{8, "WEBP", Image},
+	{4, "ftypheic", Image},
+	{4, "ftypheix", Image},
+	{4, "ftypavif", Image},
+
+	{4, "ftypM4A", Audio},
+	{0, "fLaC", Audio},
+	{0, "OggS", Audio},
+	{8, "WAVE", Audio},
+
+	{4, "ftyp", Video},
+	{0, "\x1a\x45\xdf\xa3", Video},
+	{8, "AVI ", Video},
+	{0, "\x00\x00\x01\xba", Video},
+	{0, "\x00\x00\x01\xb3", Video},
+
+	{0, "PK\x03\x04", Archive},
+	{0, "PK\x05\x06", Archive},
+	{0, "\x1f\x8b", Archive},
+	{0, "BZh", Archive},
+	{0, "\xfd7zXZ\x00", Archive},
+	{0, "7z\xbc\xaf\x27\x1c", Archive},
+	{0, "Rar!\x1a\x07", Archive},
+	{0, "\x28\xb5\x2f\xfd", Archive},
+	{257, "ustar", Archive},
+}
+
+// weakSignatures are magics short enough to begin ordinary text, such as a
+// note starting with "BM" or "ID3", so they are only checked once the
+// content is known not to be text
+var weakSignatures = []signature{
+	{0, "BM", Image},
+	{0, "ID3", Audio},
+	{0, "MThd", Audio},
+	{0, "FLV", Video},
+}
+
+// Detect reads the start of a regular file and returns its kind
+// Anything that cannot be read is Unknown
+func Detect(path string) Kind {
+	f, err := os.Open(path)
+	if err != nil {
+		return Unknown
+	}
+	defer f.Close()
+
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return Unknown
+	}
+	return detectBytes(buf[:n])
+}
+
+// detectBytes classifies content by shebang, signature table, a text check
+// and finally the standard library's content sniffing
+// An empty file has no content to tell, so it is Unknown rather than text
+func detectBytes(data []byte) Kind {
+	if len(data) == 0 {
+		return Unknown
+	}
+	if bytes.HasPrefix(data, []byte("#!")) {
+		return Script
+	}
+	if k, ok := matchSignature(data, signatures); ok {
+		return k
+	}
+	if isText(data) {
+		return Text
+	}
+	if k, ok := matchSignature(data, weakSignatures); ok {
+		return k
+	}
+
+	contentType := http.DetectContentType(data)
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return Image
+	case strings.HasPrefix(contentType, "video/"):
+		return Video
+	case strings.HasPrefix(contentType, "audio/"):
+		return Audio
+	case strings.HasPrefix(contentType, "text/"):
+		return Text
+	}
+	return Binary
+}
+
+// matchSignature returns the kind of the first signature found in data
+func matchSignature(data []byte, sigs []signature) (Kind, bool) {
+	for _, sig := range sigs {
+		end := sig.offset + len(sig.magic)
+		if end <= len(data) && string(data[sig.offset:end]) == sig.magic {
+			return sig.kind, true
+		}
+	}
+	return Unknown, false
+}
+
+// isText reports whether data looks like UTF-8 text; a multi-byte rune cut
+// off at the end of the buffer is allowed
+func isText(data []byte) bool {
+	if bytes.IndexByte(data, 0) >= 0 {
+		return false
+	}
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size == 1 {
+			return len(data) < utf8.UTFMax && !utf8.FullRune(data)
+		}
+		data = data[size:]
+	}
+	return true
+}
+
+// Matches reports whether a file of kind k satisfies a --kind filter
+// "binary" matches every kind that is not text or a script
+func Matches(k Kind, want string) bool {
+	if k == Unknown {
+		return false
+	}
+	if Kind(want) == Binary {
+		return k != Text && k != Script
+	}
+	return string(k) == want
+}
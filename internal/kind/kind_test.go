@@ -0,0 +1,60 @@
+package kind
+
+import "testing"
+
+func TestDetectBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want Kind
+	}{
+		{"empty", "", Unknown},
+		{"plain text", "hello\n", Text},
+		{"one letter", "a", Text},
+		{"utf-8 text", "café 日本\n", Text},
+		{"rune cut off at the end", "caf\xc3", Text},
+		{"shebang", "#!/bin/sh\necho hi\n", Script},
+		{"elf", "\x7fELF\x02\x01\x01\x00", ELF},
+		{"pdf", "%PDF-1.7\n", PDF},
+		{"png", "\x89PNG\r\n\x1a\n\x00\x00", Image},
+		{"gzip", "\x1f\x8b\x08\x00", Archive},
+		{"nul byte", "ab\x00cd", Binary},
+
+		// Short magics only count when the content is not text
+		{"text starting with BM", "BMW service notes\n", Text},
+		{"text starting with ID3", "ID3 tags explained\n", Text},
+		{"text starting with FLV", "FLV files\n", Text},
+		{"text starting with MThd", "MThd chunk layout\n", Text},
+		{"bitmap", "BM\x36\x00\x0c\x00\x00\x00", Image},
+		{"mp3 with ID3", "ID3\x04\x00\x00\x00\x00\x00", Audio},
+		{"flash video", "FLV\x01\x05\x00\x00\x00", Video},
+		{"midi", "MThd\x00\x00\x00\x06", Audio},
+	}
+	for _, tt := range tests {
+		if got := detectBytes([]byte(tt.data)); got != tt.want {
+			t.Errorf("%s: detectBytes(%q) = %q, want %q", tt.name, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		kind Kind
+		want string
+		ok   bool
+	}{
+		{Text, "text", true},
+		{Image, "text", false},
+		{Image, "binary", true},
+		{ELF, "binary", true},
+		{Text, "binary", false},
+		{Script, "binary", false},
+		{Unknown, "text", false},
+		{Unknown, "binary", false},
+	}
+	for _, tt := range tests {
+		if got := Matches(tt.kind, tt.want); got != tt.ok {
+			t.Errorf("Matches(%q, %q) = %v, want %v", tt.kind, tt.want, got, tt.ok)
+		}
+	}
+}
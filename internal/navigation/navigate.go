@@ -31,7 +31,7 @@ func NavigateToPath(targetPath string) bool {
 	// Get file info
 	info, err := os.Stat(targetPath)
 	if err != nil {
-		fmt.Printf("%s Directory '%s' does not exist\n", ui.Colors.Red("ERROR:"), targetPath)
+		fmt.Printf("%s Directory '%s' does not exist\n", ui.Colors.Error("ERROR:"), targetPath)
 		return false
 	}
 
@@ -43,7 +43,7 @@ func NavigateToPath(targetPath string) bool {
 	// Verify it's a valid directory
 	info, err = os.Stat(targetPath)
 	if err != nil || !info.IsDir() {
-		fmt.Printf("%s '%s' is not a valid directory\n", ui.Colors.Red("ERROR:"), targetPath)
+		fmt.Printf("%s '%s' is not a valid directory\n", ui.Colors.Error("ERROR:"), targetPath)
 		return false
 	}
 
@@ -55,7 +55,7 @@ func NavigateToPath(targetPath string) bool {
 
 	// Write path to temp file for shell integration
 	if err := writeNavPath(absPath); err != nil {
-		fmt.Printf("%s Could not save navigation path: %v\n", ui.Colors.Red("ERROR:"), err)
+		fmt.Printf("%s Could not save navigation path: %v\n", ui.Colors.Error("ERROR:"), err)
 		return false
 	}
 
@@ -69,27 +69,24 @@ func NavigateToPath(targetPath string) bool {
 	return true
 }
 
-// showDirectoryContents displays the contents of a directory
+// showDirectoryContents displays the contents of a directory with the
+// same columns as --long
 func showDirectoryContents(dirPath string) {
 	entries, err := os.ReadDir(dirPath)
 	if err != nil {
-		fmt.Printf("  %s\n", ui.Colors.Red("Could not read directory"))
+		fmt.Printf("  %s\n", ui.Colors.Error("Could not read directory"))
 		return
 	}
 
+	var table ui.LongTable
 	for _, entry := range entries {
-		info, err := entry.Info()
-		if err != nil {
-			continue
+		if info, err := entry.Info(); err == nil {
+			table.Measure(filepath.Join(dirPath, entry.Name()), info)
 		}
+	}
 
-		name := entry.Name()
-		if entry.IsDir() {
-			fmt.Printf("  %s\n", ui.Colors.Blue(name+"/"))
-		} else {
-			size := ui.FormatSize(info.Size())
-			fmt.Printf("  %s %s\n", name, ui.Colors.Dim("("+size+")"))
-		}
+	for _, entry := range entries {
+		fmt.Printf("  %s\n", table.Line(filepath.Join(dirPath, entry.Name()), entry.Name(), nil))
 	}
 	fmt.Println()
 }
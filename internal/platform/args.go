@@ -0,0 +1,10 @@
+package platform
+
+// MaxArgBytes returns how many bytes of arguments a single command line may hold
+// Platform-specific implementation in args_unix.go and args_windows.go
+func MaxArgBytes() int {
+	return maxArgBytes()
+}
+
+// maxArgBytes is the platform-specific implementation
+// Implemented in args_unix.go and args_windows.go
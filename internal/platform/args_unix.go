@@ -0,0 +1,19 @@
+//go:build unix
+
+package platform
+
+import "os"
+
+// argMax is a conservative ARG_MAX: Linux allows 2 MiB and macOS 1 MiB
+const argMax = 1 << 20
+
+// maxArgBytes returns the space left for arguments after the environment (Unix)
+func maxArgBytes() int {
+	envSize := 0
+	for _, env := range os.Environ() {
+		// Each string also costs a terminating NUL and a pointer
+		envSize += len(env) + 1 + 8
+	}
+	// Keep a safety margin for the program name and alignment
+	return argMax - envSize - 4096
+}
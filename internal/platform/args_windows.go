@@ -0,0 +1,9 @@
+//go:build windows
+
+package platform
+
+// maxArgBytes returns the command line limit (Windows)
+// CreateProcess accepts at most 32767 characters; keep a margin for quoting
+func maxArgBytes() int {
+	return 32767 - 2048
+}
@@ -0,0 +1,41 @@
+package platform
+
+import (
+	"os"
+	"sync"
+)
+
+// Owner names the user that owns path, or returns "" if it cannot be found
+// Platform-specific implementation in owner_unix.go and owner_windows.go
+func Owner(path string, info os.FileInfo) string {
+	return owner(path, info)
+}
+
+// Group names the group that owns path, or returns "" if it cannot be found
+func Group(path string, info os.FileInfo) string {
+	return group(path, info)
+}
+
+// Links returns the number of hard links to the file
+func Links(path string, info os.FileInfo) uint64 {
+	return links(path, info)
+}
+
+// Account names looked up so far, so each user and group is resolved once
+var (
+	ownerMu    sync.Mutex
+	ownerNames = map[string]string{}
+)
+
+// cachedOwner returns the name for id, calling lookup on first use
+// Users and groups share the cache, so ids carry a "u" or "g" prefix on Unix
+func cachedOwner(id string, lookup func() string) string {
+	ownerMu.Lock()
+	defer ownerMu.Unlock()
+	name, ok := ownerNames[id]
+	if !ok {
+		name = lookup()
+		ownerNames[id] = name
+	}
+	return name
+}
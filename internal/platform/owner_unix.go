@@ -0,0 +1,49 @@
+//go:build unix
+
+package platform
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// owner returns the user name for the file's uid, or the uid itself when
+// the account is unknown (Unix)
+func owner(path string, info os.FileInfo) string {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+	uid := strconv.FormatUint(uint64(stat.Uid), 10)
+	return cachedOwner("u"+uid, func() string {
+		if u, err := user.LookupId(uid); err == nil {
+			return u.Username
+		}
+		return uid
+	})
+}
+
+// group returns the group name for the file's gid, or the gid itself (Unix)
+func group(path string, info os.FileInfo) string {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+	gid := strconv.FormatUint(uint64(stat.Gid), 10)
+	return cachedOwner("g"+gid, func() string {
+		if g, err := user.LookupGroupId(gid); err == nil {
+			return g.Name
+		}
+		return gid
+	})
+}
+
+// links returns the file's hard link count (Unix)
+func links(path string, info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Nlink)
+	}
+	return 1
+}
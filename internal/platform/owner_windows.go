@@ -0,0 +1,69 @@
+//go:build windows
+
+package platform
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// owner returns the account that owns the file from its security descriptor (Windows)
+func owner(path string, info os.FileInfo) string {
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.OWNER_SECURITY_INFORMATION)
+	if err != nil {
+		return ""
+	}
+	sid, _, err := sd.Owner()
+	if err != nil || sid == nil {
+		return ""
+	}
+	return accountName(sid)
+}
+
+// group returns the primary group from the file's security descriptor (Windows)
+func group(path string, info os.FileInfo) string {
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.GROUP_SECURITY_INFORMATION)
+	if err != nil {
+		return ""
+	}
+	sid, _, err := sd.Group()
+	if err != nil || sid == nil {
+		return ""
+	}
+	return accountName(sid)
+}
+
+// accountName returns DOMAIN\account for sid, or the SID string if it is unknown
+func accountName(sid *windows.SID) string {
+	return cachedOwner(sid.String(), func() string {
+		account, domain, _, err := sid.LookupAccount("")
+		if err != nil {
+			return sid.String()
+		}
+		if domain != "" {
+			return domain + `\` + account
+		}
+		return account
+	})
+}
+
+// links returns the file's hard link count from its file information (Windows)
+func links(path string, info os.FileInfo) uint64 {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 1
+	}
+	h, err := windows.CreateFile(p, 0, windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil, windows.OPEN_EXISTING, windows.FILE_FLAG_BACKUP_SEMANTICS|windows.FILE_FLAG_OPEN_REPARSE_POINT, 0)
+	if err != nil {
+		return 1
+	}
+	defer windows.CloseHandle(h)
+
+	var data windows.ByHandleFileInformation
+	if err := windows.GetFileInformationByHandle(h, &data); err != nil {
+		return 1
+	}
+	return uint64(data.NumberOfLinks)
+}
@@ -0,0 +1,8 @@
+package platform
+
+// IsCrossDevice reports whether err is a rename that failed because source
+// and target are on different filesystems, so a copy is needed instead
+// Platform-specific implementation in rename_unix.go and rename_windows.go
+func IsCrossDevice(err error) bool {
+	return isCrossDevice(err)
+}
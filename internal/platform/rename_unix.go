@@ -0,0 +1,13 @@
+//go:build unix
+
+package platform
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isCrossDevice checks for EXDEV
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
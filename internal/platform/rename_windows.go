@@ -0,0 +1,14 @@
+//go:build windows
+
+package platform
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// isCrossDevice checks for ERROR_NOT_SAME_DEVICE
+func isCrossDevice(err error) bool {
+	return errors.Is(err, windows.ERROR_NOT_SAME_DEVICE)
+}
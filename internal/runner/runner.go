@@ -0,0 +1,349 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ReggieAlbiosA/fcf/internal/platform"
+	"github.com/ReggieAlbiosA/fcf/internal/store"
+	"github.com/ReggieAlbiosA/fcf/internal/ui"
+)
+
+// Placeholders understood in --exec and --exec-batch templates
+//
+//	{}    path                     /src/app/main.go
+//	{/}   base name                main.go
+//	{//}  parent directory         /src/app
+//	{.}   path without extension   /src/app/main
+//	{/.}  base name without ext    main
+var placeholders = []string{"{/.}", "{//}", "{/}", "{.}", "{}"}
+
+// Command is a parsed --exec / --exec-batch template
+type Command struct {
+	Args []string
+}
+
+// Parse splits a command template into arguments, honouring quotes and
+// backslash escapes. Without any placeholder, "{}" is appended
+func Parse(template string) (*Command, error) {
+	args, err := splitArgs(template)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, errors.New("command is empty")
+	}
+
+	hasPlaceholder := false
+	for _, arg := range args[1:] {
+		if containsPlaceholder(arg) {
+			hasPlaceholder = true
+			break
+		}
+	}
+	if !hasPlaceholder {
+		args = append(args, "{}")
+	}
+
+	return &Command{Args: args}, nil
+}
+
+// String returns the template as typed
+func (c *Command) String() string {
+	return strings.Join(c.Args, " ")
+}
+
+// Expand returns the command line for a single path
+func (c *Command) Expand(path string) []string {
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = expand(arg, path)
+	}
+	return args
+}
+
+// ExpandBatch returns the command line for several paths
+// Every argument holding a placeholder is repeated once per path
+func (c *Command) ExpandBatch(paths []string) []string {
+	var args []string
+	for _, arg := range c.Args {
+		if !containsPlaceholder(arg) {
+			args = append(args, arg)
+			continue
+		}
+		for _, p := range paths {
+			args = append(args, expand(arg, p))
+		}
+	}
+	return args
+}
+
+// Status records how one command invocation ended
+type Status struct {
+	Args     []string
+	ExitCode int   // -1 if the command could not be started
+	Err      error // nil on success
+}
+
+// RunEach runs the command once per path, with at most jobs running at a time
+// With a single job the command is attached to the terminal; otherwise its
+// output is buffered and printed whole so parallel commands do not interleave
+func RunEach(c *Command, paths *store.Paths, jobs int) []Status {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	statuses := make([]Status, paths.Len())
+	if jobs == 1 {
+		for i := range statuses {
+			statuses[i] = runAttached(c.Expand(paths.At(i)))
+		}
+		return statuses
+	}
+
+	var outputMu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, jobs)
+
+	for i := range statuses {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, args []string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			var stdout, stderr bytes.Buffer
+			cmd := exec.Command(args[0], args[1:]...)
+			cmd.Stdout = &stdout
+			cmd.Stderr = &stderr
+			statuses[i] = newStatus(args, cmd.Run())
+
+			outputMu.Lock()
+			os.Stdout.Write(stdout.Bytes())
+			os.Stderr.Write(stderr.Bytes())
+			outputMu.Unlock()
+		}(i, c.Expand(paths.At(i)))
+	}
+
+	wg.Wait()
+	return statuses
+}
+
+// RunBatch runs the command with all paths at once, split into several
+// invocations when the arguments would not fit on one command line
+func RunBatch(c *Command, paths *store.Paths) []Status {
+	var statuses []Status
+	chunkPaths(c, paths, platform.MaxArgBytes(), func(chunk []string) {
+		statuses = append(statuses, runAttached(c.ExpandBatch(chunk)))
+	})
+	return statuses
+}
+
+// chunkPaths splits paths so each expanded command line stays within limit
+// bytes and passes each chunk to run as soon as it is full, so only one
+// chunk of paths is held at a time
+func chunkPaths(c *Command, paths *store.Paths, limit int, run func(chunk []string)) {
+	// Fixed arguments are part of every invocation
+	base := 0
+	for _, arg := range c.Args {
+		if !containsPlaceholder(arg) {
+			base += argCost(arg)
+		}
+	}
+
+	var current []string
+	size := base
+	for i := 0; i < paths.Len(); i++ {
+		p := paths.At(i)
+		cost := 0
+		for _, arg := range c.Args {
+			if containsPlaceholder(arg) {
+				cost += argCost(expand(arg, p))
+			}
+		}
+
+		// Always put at least one path in a chunk, even if it is too long
+		if len(current) > 0 && size+cost > limit {
+			run(current)
+			current = nil
+			size = base
+		}
+		current = append(current, p)
+		size += cost
+	}
+	if len(current) > 0 {
+		run(current)
+	}
+}
+
+// argCost is the space an argument takes on the command line: the string,
+// its terminating NUL and the pointer to it
+func argCost(arg string) int {
+	return len(arg) + 1 + 8
+}
+
+// runAttached runs a command connected to the terminal
+func runAttached(args []string) Status {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return newStatus(args, cmd.Run())
+}
+
+// newStatus builds a Status from the error returned by cmd.Run
+func newStatus(args []string, err error) Status {
+	if err == nil {
+		return Status{Args: args}
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return Status{Args: args, ExitCode: exitErr.ExitCode(), Err: err}
+	}
+	return Status{Args: args, ExitCode: -1, Err: err}
+}
+
+// Failed returns the statuses of commands that did not succeed
+func Failed(statuses []Status) []Status {
+	var failed []Status
+	for _, s := range statuses {
+		if s.Err != nil {
+			failed = append(failed, s)
+		}
+	}
+	return failed
+}
+
+// ShowReport displays how many commands ran and the exit status of each failure
+func ShowReport(statuses []Status) {
+	failed := Failed(statuses)
+
+	fmt.Println()
+	fmt.Println(ui.Colors.Bold("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+	if len(failed) == 0 {
+		fmt.Println(ui.Colors.Summary(fmt.Sprintf("Ran %d command(s), all succeeded", len(statuses))))
+	} else {
+		fmt.Printf("%s %s\n",
+			ui.Colors.Yellow(fmt.Sprintf("Ran %d command(s):", len(statuses))),
+			ui.Colors.Red(fmt.Sprintf("%d failed", len(failed))))
+		for _, s := range failed {
+			label := fmt.Sprintf("exit %d", s.ExitCode)
+			if s.ExitCode < 0 {
+				label = s.Err.Error()
+			}
+			fmt.Printf("  %s %s\n", ui.Colors.Error(label+":"), displayCommand(s.Args))
+		}
+	}
+	fmt.Println(ui.Colors.Bold("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+}
+
+// displayCommand shortens a command line for the report
+func displayCommand(args []string) string {
+	const maxArgs = 6
+	if len(args) <= maxArgs {
+		return strings.Join(args, " ")
+	}
+	return fmt.Sprintf("%s ... (%d more)", strings.Join(args[:maxArgs], " "), len(args)-maxArgs)
+}
+
+// containsPlaceholder reports whether arg uses any placeholder
+func containsPlaceholder(arg string) bool {
+	for _, p := range placeholders {
+		if strings.Contains(arg, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// expand substitutes every placeholder in arg for path
+func expand(arg, path string) string {
+	if !containsPlaceholder(arg) {
+		return arg
+	}
+
+	base := filepath.Base(path)
+	values := map[string]string{
+		"{}":   path,
+		"{/}":  base,
+		"{//}": filepath.Dir(path),
+		"{.}":  strings.TrimSuffix(path, filepath.Ext(base)),
+		"{/.}": strings.TrimSuffix(base, filepath.Ext(base)),
+	}
+
+	// Replace left to right so a substituted path is never expanded again
+	var b strings.Builder
+	for i := 0; i < len(arg); {
+		matched := false
+		for _, p := range placeholders {
+			if strings.HasPrefix(arg[i:], p) {
+				b.WriteString(values[p])
+				i += len(p)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(arg[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// splitArgs splits a command line into words like a POSIX shell would,
+// without expanding variables or globs. Backslashes are kept as-is on
+// Windows, where they separate paths
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' && i+1 < len(runes) && strings.ContainsRune(`"\$`+"`", runes[i+1]) {
+				i++
+				current.WriteRune(runes[i])
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\' && filepath.Separator != '\\' && i+1 < len(runes):
+			i++
+			current.WriteRune(runes[i])
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				args = append(args, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
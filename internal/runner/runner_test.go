@@ -0,0 +1,164 @@
+package runner
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ReggieAlbiosA/fcf/internal/store"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"ls", []string{"ls"}},
+		{"  ls   -l\t{}  ", []string{"ls", "-l", "{}"}},
+		{`echo 'a b' "c d"`, []string{"echo", "a b", "c d"}},
+		{`echo ''`, []string{"echo", ""}},
+		{`echo a""b`, []string{"echo", "ab"}},
+		{`echo "it's"`, []string{"echo", "it's"}},
+		{`echo 'say "hi"'`, []string{"echo", `say "hi"`}},
+		{`echo "a \"b\" \$c \\ \n"`, []string{"echo", `a "b" $c \ \n`}},
+		{`echo 'a \' b`, []string{"echo", `a \`, "b"}},
+		{"echo 日本 'é è'", []string{"echo", "日本", "é è"}},
+	}
+	if filepath.Separator == '\\' {
+		tests = append(tests, struct {
+			line string
+			want []string
+		}{`type C:\dir\{}`, []string{"type", `C:\dir\{}`}})
+	} else {
+		tests = append(tests, []struct {
+			line string
+			want []string
+		}{
+			{`echo a\ b`, []string{"echo", "a b"}},
+			{`echo \'`, []string{"echo", "'"}},
+			{`echo a\`, []string{"echo", `a\`}},
+		}...)
+	}
+
+	for _, tt := range tests {
+		got, err := splitArgs(tt.line)
+		if err != nil {
+			t.Errorf("splitArgs(%q): %v", tt.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitArgs(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestSplitArgsUnterminated(t *testing.T) {
+	for _, line := range []string{`echo 'a`, `echo "a`, `echo "a\"`, `'`} {
+		if args, err := splitArgs(line); err == nil {
+			t.Errorf("splitArgs(%q) = %q, want an unterminated quote error", line, args)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	path := filepath.FromSlash("/src/app/main.test.go")
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"plain", "plain"},
+		{"{}", "/src/app/main.test.go"},
+		{"{/}", "main.test.go"},
+		{"{//}", "/src/app"},
+		{"{.}", "/src/app/main.test"},
+		{"{/.}", "main.test"},
+		{"{/.}.bak", "main.test.bak"},
+		{"--out={//}/{/.}.o", "--out=/src/app/main.test.o"},
+		{"{}{}", "/src/app/main.test.go/src/app/main.test.go"},
+		{"{", "{"},
+		{"{x}", "{x}"},
+		{"{/", "{/"},
+	}
+	for _, tt := range tests {
+		if got := expand(tt.arg, path); got != filepath.FromSlash(tt.want) {
+			t.Errorf("expand(%q, %q) = %q, want %q", tt.arg, path, got, tt.want)
+		}
+	}
+
+	// A substituted path is never expanded again
+	odd := filepath.FromSlash("/tmp/{}/{/}")
+	if got := expand("{}", odd); got != odd {
+		t.Errorf("expand(\"{}\", %q) = %q", odd, got)
+	}
+
+	// No extension, and a dot only in a directory name
+	for arg, want := range map[string]string{
+		"{.}":  "/src/v1.2/Makefile",
+		"{/.}": "Makefile",
+	} {
+		p := filepath.FromSlash("/src/v1.2/Makefile")
+		if got := expand(arg, p); got != filepath.FromSlash(want) {
+			t.Errorf("expand(%q, %q) = %q, want %q", arg, p, got, want)
+		}
+	}
+}
+
+func TestChunkPaths(t *testing.T) {
+	c := &Command{Args: []string{"rm", "-f", "{}"}}
+	base := argCost("rm") + argCost("-f")
+	a, b, cc := "/aaaa", "/bbbb", "/cccc"
+	each := argCost(a)
+
+	tests := []struct {
+		name  string
+		paths []string
+		limit int
+		want  [][]string
+	}{
+		{"no paths", nil, 1000, nil},
+		{"all fit", []string{a, b, cc}, base + 3*each, [][]string{{a, b, cc}}},
+		{"one byte short", []string{a, b, cc}, base + 3*each - 1, [][]string{{a, b}, {cc}}},
+		{"one per chunk", []string{a, b, cc}, base + each, [][]string{{a}, {b}, {cc}}},
+		{"too long for any chunk", []string{a, b}, 1, [][]string{{a}, {b}}},
+	}
+	for _, tt := range tests {
+		got := chunks(c, tt.paths, tt.limit)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: chunkPaths(limit %d) = %q, want %q", tt.name, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestChunkPathsRepeatedPlaceholders(t *testing.T) {
+	// Each path costs one argument per placeholder argument
+	c := &Command{Args: []string{"cp", "{}", "{/}.bak"}}
+	paths := []string{"/d/one", "/d/two", "/d/three", "/d/four"}
+	limit := argCost("cp") + argCost("/d/one") + argCost("one.bak") + argCost("/d/two") + argCost("two.bak")
+
+	got := chunks(c, paths, limit)
+	want := [][]string{{"/d/one", "/d/two"}, {"/d/three"}, {"/d/four"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("chunkPaths = %q, want %q", got, want)
+	}
+
+	// Every path is in exactly one chunk, in order
+	var all []string
+	for _, chunk := range got {
+		all = append(all, chunk...)
+	}
+	if strings.Join(all, " ") != strings.Join(paths, " ") {
+		t.Errorf("chunks hold %q, want %q", all, paths)
+	}
+}
+
+// chunks collects the chunks chunkPaths makes of paths
+func chunks(c *Command, paths []string, limit int) [][]string {
+	var all [][]string
+	chunkPaths(c, store.FromSlice(paths), limit, func(chunk []string) {
+		all = append(all, chunk)
+	})
+	return all
+}
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ReggieAlbiosA/fcf/internal/ui"
+)
+
+// fdErrorPrefix is the prefix fd puts on every line it writes with --show-errors
+const fdErrorPrefix = "[fd error]: "
+
+// newPathError builds a PathError from a walk error
+func newPathError(path string, d fs.DirEntry, err error) ui.PathError {
+	return ui.PathError{
+		Path: path,
+		Kind: ui.ClassifyError(err),
+		Dir:  d != nil && d.IsDir(),
+		Err:  err,
+	}
+}
+
+// readFdErrors reads fd's stderr until it is closed
+// Lines of the form "[fd error]: PATH: MESSAGE" become path errors; anything
+// else is returned as a plain message so a crash can be reported
+func readFdErrors(r io.Reader) ([]ui.PathError, []string) {
+	var pathErrors []ui.PathError
+	var messages []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		msg := strings.TrimPrefix(line, fdErrorPrefix)
+		sep := strings.LastIndex(msg, ": ")
+		if !strings.HasPrefix(line, fdErrorPrefix) || sep <= 0 {
+			messages = append(messages, msg)
+			continue
+		}
+
+		path, reason := msg[:sep], msg[sep+2:]
+		info, statErr := os.Lstat(path)
+		pathErrors = append(pathErrors, ui.PathError{
+			Path: path,
+			Kind: classifyFdMessage(reason),
+			Dir:  statErr == nil && info.IsDir(),
+			Err:  errors.New(reason),
+		})
+	}
+
+	return pathErrors, messages
+}
+
+// classifyFdMessage maps one of fd's error messages to an error kind
+func classifyFdMessage(msg string) ui.ErrorKind {
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.Contains(lower, "permission denied"),
+		strings.Contains(lower, "access is denied"):
+		return ui.ErrPermission
+	case strings.Contains(lower, "no such file"),
+		strings.Contains(lower, "cannot find the"):
+		return ui.ErrNotExist
+	default:
+		return ui.ErrIO
+	}
+}
+
+// fdExitedWithPathErrors reports whether fd's failing exit status is only
+// explained by unreadable paths it already reported, rather than a crash
+func fdExitedWithPathErrors(err error, pathErrors []ui.PathError) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	return exitErr.ExitCode() == 1 && len(pathErrors) > 0
+}
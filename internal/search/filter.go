@@ -0,0 +1,165 @@
+package search
+
+import (
+	"io/fs"
+
+	"github.com/ReggieAlbiosA/fcf/internal/ui"
+)
+
+// filter is the compiled set of conditions a result must meet
+// The CLI pattern and flags and a query string compile to the same nodes
+type filter struct {
+	root string
+	expr node
+	all  node // every condition, kept for highlighting after pushToFd
+
+	// Conditions fd can apply itself; expr then holds only the rest
+	fdGlob       string
+	fdIgnoreCase bool // fdGlob is only a pre-filter, rechecked in Go
+	fdType       string
+}
+
+// newFilter compiles the pattern (plain or query) and flags for a search of root
+func newFilter(pattern, root string, opts *ui.Options) (*filter, error) {
+	var expr node
+	if IsQuery(pattern) {
+		parsed, err := parseQuery(pattern, opts)
+		if err != nil {
+			return nil, err
+		}
+		expr = parsed
+	} else {
+		expr = newNameTerm(pattern, opts)
+	}
+
+	terms := []node{expr}
+	if opts.Type == "f" || opts.Type == "d" {
+		terms = append(terms, &typeTerm{kind: opts.Type})
+	}
+	// --empty and --broken-links together find either
+	switch {
+	case opts.Empty && opts.BrokenLinks:
+		terms = append(terms, &orNode{children: []node{&typeTerm{kind: "e"}, &typeTerm{kind: "b"}}})
+	case opts.Empty:
+		terms = append(terms, &typeTerm{kind: "e"})
+	case opts.BrokenLinks:
+		terms = append(terms, &typeTerm{kind: "b"})
+	}
+	if opts.Git != "" {
+		terms = append(terms, &gitTerm{filter: opts.Git})
+	}
+	if opts.Kind != "" {
+		terms = append(terms, &kindTerm{kind: opts.Kind})
+	}
+
+	expr = flattenAnd(terms)
+	return &filter{root: root, expr: expr, all: expr}, nil
+}
+
+// flattenAnd joins terms with AND, merging nested AND nodes
+// Kind terms read file contents, so they go last and only run on entries
+// that passed every cheaper term
+func flattenAnd(terms []node) node {
+	var children, last []node
+	for _, t := range terms {
+		if and, ok := t.(*andNode); ok {
+			for _, c := range and.children {
+				if _, ok := c.(*kindTerm); ok {
+					last = append(last, c)
+				} else {
+					children = append(children, c)
+				}
+			}
+		} else if _, ok := t.(*kindTerm); ok {
+			last = append(last, t)
+		} else if t != nil {
+			children = append(children, t)
+		}
+	}
+	children = append(children, last...)
+	switch len(children) {
+	case 0:
+		return nil
+	case 1:
+		return children[0]
+	default:
+		return &andNode{children: children}
+	}
+}
+
+// conjuncts returns the top-level AND terms of expr
+func conjuncts(expr node) []node {
+	if and, ok := expr.(*andNode); ok {
+		return and.children
+	}
+	if expr == nil {
+		return nil
+	}
+	return []node{expr}
+}
+
+// pushToFd moves the first name pattern and a file/directory type into fd's
+// own arguments, leaving the remaining conditions to be checked in Go
+// Case-insensitive names stay in Go: fd -i only does simple case folding,
+// so ß would not match SS as it does with the walkers
+func (f *filter) pushToFd() {
+	var rest []node
+	for _, term := range conjuncts(f.expr) {
+		switch t := term.(type) {
+		case *nameTerm:
+			if f.fdGlob == "" && !t.ignoreCase {
+				f.fdGlob = t.pattern
+				continue
+			}
+			// fd folds case rune by rune, so it may reject names that only
+			// match with full folding (Straße for STRASSE*); its match is a
+			// pre-filter and the term stays to recheck what fd lets through
+			if f.fdGlob == "" && foldsSimply(t.pattern) {
+				f.fdGlob = t.pattern
+				f.fdIgnoreCase = true
+			}
+		case *typeTerm:
+			if f.fdType == "" && (t.kind == "f" || t.kind == "d") {
+				f.fdType = t.kind
+				continue
+			}
+		}
+		rest = append(rest, term)
+	}
+
+	if f.fdGlob == "" {
+		f.fdGlob = "*"
+	}
+	f.expr = flattenAnd(rest)
+}
+
+// match reports whether path, found during the search, meets every condition
+// d may be nil when the path came from fd
+func (f *filter) match(path string, d fs.DirEntry) bool {
+	if f.expr == nil {
+		return true
+	}
+	return f.expr.eval(newEntry(f.root, path, d))
+}
+
+// prune reports whether a directory can be skipped entirely because a
+// top-level "NOT path:..." term excludes it, and so everything below it
+func (f *filter) prune(path string, d fs.DirEntry) bool {
+	var e *entry
+	for _, term := range conjuncts(f.expr) {
+		not, ok := term.(*notNode)
+		if !ok {
+			continue
+		}
+		if _, ok := not.child.(*pathTerm); !ok {
+			continue
+		}
+		if e == nil {
+			e = newEntry(f.root, path, d)
+		}
+		if not.child.eval(e) {
+			return true
+		}
+	}
+	return false
+}
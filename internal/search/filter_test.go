@@ -0,0 +1,64 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ReggieAlbiosA/fcf/internal/ui"
+)
+
+func TestPushToFd(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		opts       ui.Options
+		glob       string
+		ignoreCase bool
+		recheck    bool // the name term is still evaluated in Go
+	}{
+		{"*.go", ui.Options{}, "*.go", false, false},
+		{"*.go", ui.Options{IgnoreCase: true}, "*.go", true, true},
+		{"readme*", ui.Options{SmartCase: true}, "readme*", true, true},
+		{"README*", ui.Options{SmartCase: true}, "README*", false, false},
+		{"straße*", ui.Options{IgnoreCase: true}, "*", false, true}, // ß folds to two runes
+		{"ﬁle*", ui.Options{IgnoreCase: true}, "*", false, true},
+		{"name:*.go size:>1k", ui.Options{}, "*.go", false, false},
+	}
+	for _, tt := range tests {
+		f, err := newFilter(tt.pattern, "/", &tt.opts)
+		if err != nil {
+			t.Errorf("newFilter(%q): %v", tt.pattern, err)
+			continue
+		}
+		f.pushToFd()
+		if f.fdGlob != tt.glob || f.fdIgnoreCase != tt.ignoreCase {
+			t.Errorf("%q: fd glob %q ignoring case %v, want %q %v", tt.pattern, f.fdGlob, f.fdIgnoreCase, tt.glob, tt.ignoreCase)
+		}
+		if recheck := hasNameTerm(f.expr); recheck != tt.recheck {
+			t.Errorf("%q: name term rechecked in Go = %v, want %v", tt.pattern, recheck, tt.recheck)
+		}
+
+		args := strings.Join(f.fdArgs("/"), " ")
+		flag := " -s "
+		if tt.ignoreCase {
+			flag = " -i "
+		}
+		if !strings.Contains(args, flag) {
+			t.Errorf("%q: fd arguments %q lack %q", tt.pattern, args, strings.TrimSpace(flag))
+		}
+	}
+}
+
+// hasNameTerm reports whether n holds a name term
+func hasNameTerm(n node) bool {
+	switch t := n.(type) {
+	case *nameTerm:
+		return true
+	case *andNode:
+		for _, c := range t.children {
+			if hasNameTerm(c) {
+				return true
+			}
+		}
+	}
+	return false
+}
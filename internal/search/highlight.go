@@ -0,0 +1,118 @@
+package search
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/ReggieAlbiosA/fcf/internal/ui"
+)
+
+// spans returns the parts of path that the name, path and ext terms matched,
+// as byte ranges into path, for highlighting
+// Terms under NOT matched nothing, and terms such as size or type have no
+// position in the path, so they add no spans
+func (f *filter) spans(path string) []ui.Span {
+	if f.all == nil {
+		return nil
+	}
+	return termSpans(f.all, newEntry(f.root, path, nil))
+}
+
+// termSpans collects the spans of the terms of n that matched e
+func termSpans(n node, e *entry) []ui.Span {
+	switch t := n.(type) {
+	case *andNode:
+		var spans []ui.Span
+		for _, c := range t.children {
+			spans = append(spans, termSpans(c, e)...)
+		}
+		return spans
+	case *orNode:
+		var spans []ui.Span
+		for _, c := range t.children {
+			if c.eval(e) {
+				spans = append(spans, termSpans(c, e)...)
+			}
+		}
+		return spans
+	case *nameTerm:
+		return t.spans(e)
+	case *pathTerm:
+		return t.spans(e)
+	case *extTerm:
+		return t.spans(e)
+	}
+	return nil
+}
+
+// spans returns the characters of the base name the glob matched; when
+// they cannot be told apart the whole name is highlighted
+func (t *nameTerm) spans(e *entry) []ui.Span {
+	name := e.name()
+	if !t.m.match(name) {
+		return nil
+	}
+	start := len(e.path) - len(name)
+	spans, ok := t.m.spans(name)
+	if !ok {
+		return []ui.Span{{Start: start, End: len(e.path)}}
+	}
+	return shiftSpans(spans, start)
+}
+
+// spans returns every occurrence of the text, or the components matched
+// by the glob, in the part of the path below the search root
+func (t *pathTerm) spans(e *entry) []ui.Span {
+	if e.rel == "." || !strings.HasSuffix(filepath.ToSlash(e.path), e.rel) {
+		return nil
+	}
+	start := len(e.path) - len(e.rel)
+
+	var spans []ui.Span
+	if t.glob != nil {
+		offset := start
+		for _, part := range strings.Split(e.rel, "/") {
+			if t.glob.match(part) {
+				spans = append(spans, ui.Span{Start: offset, End: offset + len(part)})
+			}
+			offset += len(part) + 1
+		}
+		return spans
+	}
+
+	rel := e.rel
+	if t.fold {
+		rel = folder.String(rel)
+		// Folding changed lengths, so offsets in rel are not offsets in path
+		if len(rel) != len(e.rel) {
+			return []ui.Span{{Start: start, End: len(e.path)}}
+		}
+	}
+	for i := 0; t.text != ""; {
+		j := strings.Index(rel[i:], t.text)
+		if j < 0 {
+			break
+		}
+		spans = append(spans, ui.Span{Start: start + i + j, End: start + i + j + len(t.text)})
+		i += j + len(t.text)
+	}
+	return spans
+}
+
+// spans returns the extension, with its dot, when it is one of the wanted ones
+func (t *extTerm) spans(e *entry) []ui.Span {
+	if !t.eval(e) {
+		return nil
+	}
+	ext := filepath.Ext(e.path)
+	return []ui.Span{{Start: len(e.path) - len(ext), End: len(e.path)}}
+}
+
+// shiftSpans moves spans by offset bytes
+func shiftSpans(spans []ui.Span, offset int) []ui.Span {
+	for i := range spans {
+		spans[i].Start += offset
+		spans[i].End += offset
+	}
+	return spans
+}
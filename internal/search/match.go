@@ -0,0 +1,288 @@
+package search
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/ReggieAlbiosA/fcf/internal/ui"
+)
+
+// folder applies full Unicode case folding (e.g. "ß" and "SS" both fold to "ss")
+var folder = cases.Fold()
+
+// matcher matches file names against a glob pattern
+// Names and pattern are compared in NFC form, so names stored decomposed
+// (NFD, as macOS does) still match a pattern typed with precomposed accents
+type matcher struct {
+	pattern    string
+	ignoreCase bool
+}
+
+// newMatcher prepares pattern for matching
+func newMatcher(pattern string, ignoreCase bool) *matcher {
+	pattern = norm.NFC.String(pattern)
+	if ignoreCase {
+		pattern = folder.String(pattern)
+	}
+	return &matcher{pattern: pattern, ignoreCase: ignoreCase}
+}
+
+// match checks if name matches the glob pattern
+func (m *matcher) match(name string) bool {
+	name = norm.NFC.String(name)
+	if m.ignoreCase {
+		name = folder.String(name)
+	}
+
+	matched, err := filepath.Match(m.pattern, name)
+	if err != nil {
+		return false
+	}
+	return matched
+}
+
+// spans returns the byte ranges of name matched by the pattern's literal
+// characters and classes, which is what a highlight should show; * and ?
+// explain nothing. ok is false when name does not match, or is not in NFC
+// form so positions in the normalized name do not map back to it
+func (m *matcher) spans(name string) (spans []ui.Span, ok bool) {
+	if !norm.NFC.IsNormalString(name) {
+		return nil, false
+	}
+	toks := globTokens(m.pattern)
+
+	// The runes compared against the pattern, and the bytes of name each
+	// came from; folding can turn one rune into several (ß into ss)
+	var runes []rune
+	var from []ui.Span
+	for i, r := range name {
+		src := ui.Span{Start: i, End: i + utf8.RuneLen(r)}
+		if !m.ignoreCase {
+			runes = append(runes, r)
+			from = append(from, src)
+			continue
+		}
+		for _, f := range folder.String(string(r)) {
+			runes = append(runes, f)
+			from = append(from, src)
+		}
+	}
+
+	// Greedy matching with backtracking to the last star; hits made since
+	// that star are dropped when it has to take one more rune
+	var hits []int
+	p, n := 0, 0
+	star, starN, starHits := -1, 0, 0
+	for n < len(runes) {
+		if p < len(toks) && toks[p].kind != '*' && toks[p].matches(runes[n]) {
+			if toks[p].kind != '?' {
+				hits = append(hits, n)
+			}
+			p++
+			n++
+			continue
+		}
+		if p < len(toks) && toks[p].kind == '*' {
+			star, starN, starHits = p, n, len(hits)
+			p++
+			continue
+		}
+		if star < 0 {
+			return nil, false
+		}
+		p = star + 1
+		starN++
+		n = starN
+		hits = hits[:starHits]
+	}
+	for p < len(toks) && toks[p].kind == '*' {
+		p++
+	}
+	if p != len(toks) {
+		return nil, false
+	}
+
+	for _, h := range hits {
+		src := from[h]
+		if last := len(spans) - 1; last >= 0 && spans[last].End >= src.Start {
+			spans[last].End = src.End
+		} else {
+			spans = append(spans, src)
+		}
+	}
+	return spans, true
+}
+
+// globToken is one element of a glob: a literal rune, '*', '?' or a '[' class
+type globToken struct {
+	kind  rune // 0 for a literal
+	r     rune
+	class string // the class with its brackets, for filepath.Match
+}
+
+// matches reports whether the token matches one rune
+func (t globToken) matches(r rune) bool {
+	switch t.kind {
+	case '?':
+		return true
+	case '[':
+		ok, _ := filepath.Match(t.class, string(r))
+		return ok
+	default:
+		return t.r == r
+	}
+}
+
+// globTokens splits a glob into tokens, as filepath.Match reads it
+func globTokens(pattern string) []globToken {
+	runes := []rune(pattern)
+	var toks []globToken
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch r {
+		case '*':
+			if len(toks) == 0 || toks[len(toks)-1].kind != '*' {
+				toks = append(toks, globToken{kind: '*'})
+			}
+		case '?':
+			toks = append(toks, globToken{kind: '?'})
+		case '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				if runes[end] == '\\' && filepath.Separator != '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(runes) {
+				toks = append(toks, globToken{r: r})
+				continue
+			}
+			toks = append(toks, globToken{kind: '[', class: string(runes[i : end+1])})
+			i = end
+		case '\\':
+			if i+1 < len(runes) && filepath.Separator != '\\' {
+				i++
+			}
+			toks = append(toks, globToken{r: runes[i]})
+		default:
+			toks = append(toks, globToken{r: r})
+		}
+	}
+	return toks
+}
+
+// foldsSimply reports whether every rune of pattern case-folds to a single
+// rune, as fd's --ignore-case does, so fd finds every name it matches
+func foldsSimply(pattern string) bool {
+	for _, r := range pattern {
+		if utf8.RuneCountInString(folder.String(string(r))) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// isIgnoreCase decides whether pattern is matched case-insensitively
+// -s forces case-sensitive matching, -i forces insensitive, and with
+// --smart-case a pattern without uppercase letters matches any case
+func isIgnoreCase(pattern string, opts *ui.Options) bool {
+	switch {
+	case opts.CaseSensitive:
+		return false
+	case opts.IgnoreCase:
+		return true
+	case opts.SmartCase:
+		return !hasUpper(pattern)
+	default:
+		return false
+	}
+}
+
+// hasUpper reports whether s contains an uppercase letter
+func hasUpper(s string) bool {
+	for _, r := range s {
+		if unicode.IsUpper(r) || unicode.IsTitle(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// needsNormalization reports whether pattern has characters whose NFD form
+// differs, so fd's glob could miss names stored decomposed
+func needsNormalization(pattern string) bool {
+	for _, r := range pattern {
+		if r >= utf8.RuneSelf && norm.NFD.String(string(r)) != string(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// globToFdRegex converts a glob pattern to an anchored regex for fd in which
+// every accented character also matches its decomposed form
+func globToFdRegex(pattern string) string {
+	runes := []rune(norm.NFC.String(pattern))
+
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			// Copy the character class, translating negation
+			end := i + 1
+			if end < len(runes) && (runes[end] == '^' || runes[end] == '!') {
+				end++
+			}
+			if end < len(runes) && runes[end] == ']' {
+				end++
+			}
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end >= len(runes) {
+				b.WriteString(regexp.QuoteMeta(string(r)))
+				continue
+			}
+			class := string(runes[i+1 : end])
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i = end
+		case '\\':
+			if i+1 < len(runes) && filepath.Separator != '\\' {
+				i++
+				b.WriteString(quoteNormalized(runes[i]))
+			} else {
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		default:
+			b.WriteString(quoteNormalized(r))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// quoteNormalized quotes r for a regex, matching both its NFC and NFD forms
+func quoteNormalized(r rune) string {
+	composed := string(r)
+	decomposed := norm.NFD.String(composed)
+	if decomposed == composed {
+		return regexp.QuoteMeta(composed)
+	}
+	return "(?:" + regexp.QuoteMeta(composed) + "|" + regexp.QuoteMeta(decomposed) + ")"
+}
@@ -0,0 +1,142 @@
+package search
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ReggieAlbiosA/fcf/internal/ui"
+)
+
+// marked shows spans as brackets around the bytes they cover; overlapping
+// and touching spans are shown as one
+func marked(s string, spans []ui.Span) string {
+	sorted := append([]ui.Span(nil), spans...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	var b strings.Builder
+	prev := 0
+	for i := 0; i < len(sorted); i++ {
+		start, end := sorted[i].Start, sorted[i].End
+		for i+1 < len(sorted) && sorted[i+1].Start <= end {
+			i++
+			end = max(end, sorted[i].End)
+		}
+		if start < prev || end < start || end > len(s) {
+			return fmt.Sprintf("%s with bad spans %v", s, spans)
+		}
+		b.WriteString(s[prev:start] + "[" + s[start:end] + "]")
+		prev = end
+	}
+	b.WriteString(s[prev:])
+	return b.String()
+}
+
+func TestMatcherSpans(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		ignoreCase bool
+		name       string
+		want       string // name with the spans in brackets, "" for no match
+	}{
+		{"main.go", false, "main.go", "[main.go]"},
+		{"*.go", false, "main.go", "main[.go]"},
+		{"ab*", false, "abc", "[ab]c"},
+		{"*", false, "anything", "anything"},
+		{"?x", false, "ax", "a[x]"},
+		{"a?c", false, "abc", "[a]b[c]"},
+		{"[abc]*", false, "cat", "[c]at"},
+		{"[^a]b", false, "cb", "[cb]"},
+		{"[^a]b", false, "ab", ""},
+		{"[a-z][0-9]", false, "x7", "[x7]"},
+		{"a*b*c", false, "aXbYc", "[a]X[b]Y[c]"},
+		{"*a*", false, "banana", "b[a]nana"},
+		{"*ab", false, "aab", "a[ab]"}, // the first a is dropped on backtracking
+		{"*.tar.gz", false, "x.tar.tar.gz", "x.tar[.tar.gz]"},
+		{"**x", false, "yx", "y[x]"},
+		{"日本*", false, "日本語.txt", "[日本]語.txt"},
+		{"*語*", false, "日本語.txt", "日本[語].txt"},
+		{"*.go", false, "main.GO", ""},
+		{"*.go", false, "main.go.bak", ""},
+		{"abc", false, "ab", ""},
+		{"*.GO", true, "MAIN.Go", "MAIN[.Go]"},
+		{"readme*", true, "README.md", "[README].md"},
+		{"*ss*", true, "Straße", "Stra[ß]e"},                    // ß folds to ss
+		{"stra?e", true, "Straße", ""},                          // ? is one folded rune, and ß is two
+		{"*\u00e9*", false, "caf\u00e9.txt", "caf[\u00e9].txt"}, // precomposed
+		{"caf*", false, "cafe\u0301.txt", ""},                   // decomposed: no positions
+		{"[[]x", false, "[x", "[[x]"},
+	}
+	if filepath.Separator != '\\' {
+		tests = append(tests, []struct {
+			pattern    string
+			ignoreCase bool
+			name       string
+			want       string
+		}{
+			{`\*.go`, false, "*.go", "[*.go]"},
+			{`a\?`, false, "a?", "[a?]"},
+		}...)
+	}
+
+	for _, tt := range tests {
+		m := newMatcher(tt.pattern, tt.ignoreCase)
+		spans, ok := m.spans(tt.name)
+		got := ""
+		if ok {
+			got = marked(tt.name, spans)
+		}
+		if got != tt.want {
+			t.Errorf("newMatcher(%q, %v).spans(%q) = %q, want %q", tt.pattern, tt.ignoreCase, tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestMatcherSpansAgreeWithMatch checks that spans finds a match exactly
+// when match does, for names in NFC form
+func TestMatcherSpansAgreeWithMatch(t *testing.T) {
+	patterns := []string{"*", "*.go", "a*b", "*a*a*", "?", "[ab]*[cd]", "x*y*z", "*ß*", "*_test.go"}
+	names := []string{"", "a", "ab", "aab", "ba", "main.go", "main_test.go", "xyz", "xaybzc", "aacd", "straße", "STRASSE", "ß"}
+	for _, pattern := range patterns {
+		for _, ignoreCase := range []bool{false, true} {
+			m := newMatcher(pattern, ignoreCase)
+			for _, name := range names {
+				_, ok := m.spans(name)
+				if want := m.match(name); ok != want {
+					t.Errorf("newMatcher(%q, %v): spans(%q) ok = %v, match = %v", pattern, ignoreCase, name, ok, want)
+				}
+			}
+		}
+	}
+}
+
+func TestFilterSpans(t *testing.T) {
+	root := filepath.FromSlash("/r")
+	tests := []struct {
+		query string
+		path  string
+		want  string
+	}{
+		{"*.go", "/r/src/main.go", "/r/src/main[.go]"},
+		{"ext:go", "/r/src/main.go", "/r/src/main[.go]"},
+		{"path:src *.go", "/r/src/main.go", "/r/[src]/main[.go]"},
+		{"*.go OR *.txt", "/r/notes.txt", "/r/notes[.txt]"},
+		{"*.go NOT path:vendor", "/r/a.go", "/r/a[.go]"},
+		{"size:>0 *.go", "/r/a.go", "/r/a[.go]"},
+		{"type:f", "/r/a.go", "/r/a.go"},
+	}
+	for _, tt := range tests {
+		f, err := newFilter(tt.query, root, &ui.Options{})
+		if err != nil {
+			t.Errorf("newFilter(%q): %v", tt.query, err)
+			continue
+		}
+		path := filepath.FromSlash(tt.path)
+		got := marked(path, f.spans(path))
+		if want := filepath.FromSlash(tt.want); got != want {
+			t.Errorf("query %q on %q: spans %q, want %q", tt.query, path, got, want)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package search
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ReggieAlbiosA/fcf/internal/ui"
+)
+
+// stableRuns is how many top-level directories fd searches at once with
+// --stable; finished ones wait, sorted in memory, until their turn
+const stableRuns = 4
+
+// fdBatch is what one fd run found, in walk order
+type fdBatch struct {
+	paths      []string
+	pathErrors []ui.PathError
+	err        error
+}
+
+// searchFdStable streams fd's matches in the order the walker finds them.
+// fd runs once for the top level of searchPath and once for each directory
+// in it, several at a time, and each directory's matches are shown as soon
+// as it and every directory before it are done, so only a few directories'
+// matches are held back and --max-results stops fd early
+func searchFdStable(f *filter, searchPath string, opts *ui.Options, stopChan <-chan struct{}, progress *ui.Progress, result *SearchResult) error {
+	entries, err := os.ReadDir(searchPath)
+	if err != nil {
+		return err
+	}
+
+	// Closed once results stop being shown, to kill the fd runs left
+	done := make(chan struct{})
+	defer close(done)
+
+	// collect runs fd on dir and returns its matches sorted
+	collect := func(dir string, extra ...string) fdBatch {
+		p, err := startFd(f.fdArgs(dir, extra...))
+		if err != nil {
+			return fdBatch{err: err}
+		}
+		exited := make(chan struct{})
+		go func() {
+			select {
+			case <-stopChan:
+				p.cmd.Process.Kill()
+			case <-done:
+				p.cmd.Process.Kill()
+			case <-exited:
+			}
+		}()
+
+		var batch fdBatch
+		scanner := bufio.NewScanner(p.stdout)
+		for scanner.Scan() {
+			line := fdLine(scanner.Text())
+			if line == "" {
+				continue
+			}
+			progress.AddEntry(line)
+			if f.match(line, nil) {
+				batch.paths = append(batch.paths, line)
+			}
+		}
+		batch.err = p.wait()
+		close(exited)
+		batch.pathErrors = p.pathErrors
+		sortDFS(batch.paths)
+		return batch
+	}
+
+	top := collect(searchPath, "--max-depth", "1")
+	if err := rootError(top.pathErrors, searchPath); err != nil {
+		return err
+	}
+	if stopped(stopChan) {
+		result.Stopped = true
+		return nil
+	}
+	if top.err != nil {
+		return top.err
+	}
+	result.Errors = append(result.Errors, top.pathErrors...)
+	topMatches := make(map[string]bool, len(top.paths))
+	for _, path := range top.paths {
+		topMatches[path] = true
+	}
+
+	// Directories fd descends into, in walk order, each with the channel
+	// its batch arrives on
+	var dirs []string
+	for _, e := range entries {
+		path := filepath.Join(searchPath, e.Name())
+		if e.IsDir() && !f.prune(path, e) {
+			dirs = append(dirs, path)
+		}
+	}
+	batches := make([]chan fdBatch, len(dirs))
+	for i := range batches {
+		batches[i] = make(chan fdBatch, 1)
+	}
+
+	// A run starts once a slot is free; a slot is freed when its batch is shown
+	slots := make(chan struct{}, stableRuns)
+	go func() {
+		for i, dir := range dirs {
+			select {
+			case slots <- struct{}{}:
+			case <-done:
+				return
+			}
+			i, dir := i, dir
+			go func() { batches[i] <- collect(dir) }()
+		}
+	}()
+
+	// show adds a match; false means the search is over
+	show := func(path string) bool {
+		if stopped(stopChan) {
+			result.Stopped = true
+			return false
+		}
+		return result.addResult(path, opts)
+	}
+
+	next := 0
+	for _, e := range entries {
+		path := filepath.Join(searchPath, e.Name())
+		if topMatches[path] && !show(path) {
+			return nil
+		}
+		if next == len(dirs) || dirs[next] != path {
+			continue
+		}
+
+		batch := <-batches[next]
+		<-slots
+		next++
+		if stopped(stopChan) {
+			result.Stopped = true
+			return nil
+		}
+		if batch.err != nil {
+			return batch.err
+		}
+		result.Errors = append(result.Errors, batch.pathErrors...)
+		for _, match := range batch.paths {
+			if !show(match) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// stopped reports whether a stop has been requested
+func stopped(stopChan <-chan struct{}) bool {
+	select {
+	case <-stopChan:
+		return true
+	default:
+		return false
+	}
+}
+
+// sortDFS sorts paths into the order filepath.WalkDir visits them: depth
+// first, each directory's entries by name, a directory before its contents
+func sortDFS(paths []string) {
+	sort.SliceStable(paths, func(i, j int) bool {
+		return lessDFS(paths[i], paths[j])
+	})
+}
+
+// lessDFS compares paths one component at a time, so "a/b" sorts before
+// "a.txt" as it does in a walk, rather than after it as in a plain string sort
+func lessDFS(a, b string) bool {
+	sep := string(filepath.Separator)
+	for {
+		aHead, aRest, aMore := strings.Cut(a, sep)
+		bHead, bRest, bMore := strings.Cut(b, sep)
+		if aHead != bHead {
+			return aHead < bHead
+		}
+		if !aMore || !bMore {
+			// One path is a prefix of the other: the parent comes first
+			return !aMore && bMore
+		}
+		a, b = aRest, bRest
+	}
+}
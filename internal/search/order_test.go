@@ -0,0 +1,99 @@
+package search
+
+import (
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSortDFS(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+		want  []string
+	}{
+		{
+			name:  "directory before its contents",
+			paths: []string{"a/b", "a"},
+			want:  []string{"a", "a/b"},
+		},
+		{
+			name:  "contents before a sibling that sorts after the separator",
+			paths: []string{"a.txt", "a/b", "a", "a-b"},
+			want:  []string{"a", "a/b", "a-b", "a.txt"},
+		},
+		{
+			name:  "depth first",
+			paths: []string{"b", "a/z", "a/b/c", "a/b", "a"},
+			want:  []string{"a", "a/b", "a/b/c", "a/z", "b"},
+		},
+		{
+			name:  "byte order, as os.ReadDir sorts",
+			paths: []string{"b", "B", "a", "_"},
+			want:  []string{"B", "_", "a", "b"},
+		},
+		{
+			name:  "absolute paths",
+			paths: []string{"/r/x.go", "/r/x/y.go", "/r/w.go"},
+			want:  []string{"/r/w.go", "/r/x/y.go", "/r/x.go"},
+		},
+		{
+			name:  "duplicates kept",
+			paths: []string{"a/b", "a", "a/b"},
+			want:  []string{"a", "a/b", "a/b"},
+		},
+		{
+			name:  "empty",
+			paths: nil,
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		paths := make([]string, len(tt.paths))
+		copy(paths, tt.paths)
+		for i := range paths {
+			paths[i] = filepath.FromSlash(paths[i])
+		}
+		want := make([]string, len(tt.want))
+		for i := range tt.want {
+			want[i] = filepath.FromSlash(tt.want[i])
+		}
+
+		sortDFS(paths)
+		if !reflect.DeepEqual(paths, want) {
+			t.Errorf("%s: sortDFS(%q) = %q, want %q", tt.name, tt.paths, paths, want)
+		}
+	}
+}
+
+// TestSortDFSMatchesWalk checks sortDFS against the order of a real walk
+func TestSortDFSMatchesWalk(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.txt":       {},
+		"a/b.txt":     {},
+		"a/b/c.txt":   {},
+		"a-b/x":       {},
+		"a b/y":       {},
+		"Z/z":         {},
+		"ab/ab/ab/ab": {},
+		"ab.d/e":      {},
+	}
+	var walked []string
+	fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if path != "." {
+			walked = append(walked, filepath.FromSlash(path))
+		}
+		return err
+	})
+
+	paths := make([]string, len(walked))
+	for i := range walked {
+		paths[len(walked)-1-i] = walked[i]
+	}
+	sortDFS(paths)
+	if !reflect.DeepEqual(paths, walked) {
+		t.Errorf("sortDFS = %q, want the walk order %q", paths, walked)
+	}
+}
@@ -0,0 +1,756 @@
+package search
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ReggieAlbiosA/fcf/internal/git"
+	"github.com/ReggieAlbiosA/fcf/internal/kind"
+	"github.com/ReggieAlbiosA/fcf/internal/platform"
+	"github.com/ReggieAlbiosA/fcf/internal/ui"
+)
+
+// Query syntax
+//
+//	name:*.log size:>10M mtime:<7d type:f -path:node_modules
+//	(ext:jpg | ext:png) NOT path:cache
+//
+// Terms next to each other must all match (AND); OR or | matches either
+// side; NOT, - or ! negates; parentheses group. A word without a field is a
+// name pattern, so a plain pattern behaves exactly like the CLI argument.
+
+// queryFields lists the fields understood in a query
+var queryFields = []string{"name", "path", "ext", "type", "size", "mtime", "git", "kind"}
+
+// QueryError describes a query syntax error at a character position
+type QueryError struct {
+	Pos int // 0-based rune offset into the query
+	Msg string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Pos+1, e.Msg)
+}
+
+// IsQuery reports whether text is a query rather than a plain pattern: it
+// must look like one and parse. Anything else, such as 'Tom & Jerry*' or a
+// query with a syntax error, is matched as a plain name pattern
+func IsQuery(text string) bool {
+	return LooksLikeQuery(text) && ValidateQuery(text) == nil
+}
+
+// LooksLikeQuery reports whether text contains a field term or an explicit
+// OR/AND/NOT keyword; the symbols |, & and - alone do not count, since they
+// are common in file names
+func LooksLikeQuery(text string) bool {
+	tokens, err := lexQuery(text)
+	if err != nil {
+		return false
+	}
+	for _, tok := range tokens {
+		switch tok.kind {
+		case tokOr, tokAnd, tokNot:
+			if tok.text == "OR" || tok.text == "AND" || tok.text == "NOT" {
+				return true
+			}
+		case tokTerm:
+			if field, _, ok := splitField(tok.text); ok && isQueryField(field) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ValidateQuery checks query syntax without searching
+func ValidateQuery(text string) error {
+	_, err := parseQuery(text, &ui.Options{})
+	return err
+}
+
+// entry is a candidate path with its details loaded on demand
+type entry struct {
+	path string
+	rel  string      // path relative to the search root, slash-separated
+	d    fs.DirEntry // nil for fd results
+
+	info    fs.FileInfo
+	infoErr error
+	loaded  bool
+}
+
+// newEntry creates an entry for path found under root
+func newEntry(root, path string, d fs.DirEntry) *entry {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		rel = path
+	}
+	return &entry{path: path, rel: filepath.ToSlash(rel), d: d}
+}
+
+// name returns the base name of the entry
+func (e *entry) name() string {
+	if e.d != nil {
+		return e.d.Name()
+	}
+	return filepath.Base(e.path)
+}
+
+// lstat returns the entry's file info without following symlinks
+func (e *entry) lstat() (fs.FileInfo, error) {
+	if !e.loaded {
+		e.loaded = true
+		if e.d != nil {
+			e.info, e.infoErr = e.d.Info()
+		} else {
+			e.info, e.infoErr = os.Lstat(e.path)
+		}
+	}
+	return e.info, e.infoErr
+}
+
+// isDir reports whether the entry is a directory
+func (e *entry) isDir() bool {
+	if e.d != nil {
+		return e.d.IsDir()
+	}
+	info, err := e.lstat()
+	return err == nil && info.IsDir()
+}
+
+// node is a compiled query condition
+type node interface {
+	eval(e *entry) bool
+}
+
+type andNode struct{ children []node }
+type orNode struct{ children []node }
+type notNode struct{ child node }
+
+func (n *andNode) eval(e *entry) bool {
+	for _, c := range n.children {
+		if !c.eval(e) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *orNode) eval(e *entry) bool {
+	for _, c := range n.children {
+		if c.eval(e) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *notNode) eval(e *entry) bool {
+	return !n.child.eval(e)
+}
+
+// nameTerm matches the base name against a glob
+type nameTerm struct {
+	pattern    string
+	ignoreCase bool
+	m          *matcher
+}
+
+func newNameTerm(pattern string, opts *ui.Options) *nameTerm {
+	ignoreCase := isIgnoreCase(pattern, opts)
+	return &nameTerm{pattern: pattern, ignoreCase: ignoreCase, m: newMatcher(pattern, ignoreCase)}
+}
+
+func (t *nameTerm) eval(e *entry) bool {
+	return t.m.match(e.name())
+}
+
+// pathTerm matches text anywhere in the path relative to the search root,
+// or a glob against any of its components
+type pathTerm struct {
+	text string
+	glob *matcher
+	fold bool
+}
+
+func (t *pathTerm) eval(e *entry) bool {
+	if t.glob != nil {
+		for _, part := range strings.Split(e.rel, "/") {
+			if t.glob.match(part) {
+				return true
+			}
+		}
+		return false
+	}
+	if t.fold {
+		return strings.Contains(folder.String(e.rel), t.text)
+	}
+	return strings.Contains(e.rel, t.text)
+}
+
+// extTerm matches one of several file extensions
+type extTerm struct{ exts []string }
+
+func (t *extTerm) eval(e *entry) bool {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(e.name()), "."))
+	for _, want := range t.exts {
+		if ext == want {
+			return true
+		}
+	}
+	return false
+}
+
+// typeTerm matches the entry type: f(ile), d(irectory), l(ink), x (executable),
+// e (empty file or directory) or b (broken symlink)
+type typeTerm struct{ kind string }
+
+func (t *typeTerm) eval(e *entry) bool {
+	switch t.kind {
+	case "f":
+		return !e.isDir()
+	case "d":
+		return e.isDir()
+	case "l":
+		info, err := e.lstat()
+		return err == nil && info.Mode()&os.ModeSymlink != 0
+	case "x":
+		return !e.isDir() && platform.IsExecutable(e.path)
+	case "e":
+		return isEmpty(e)
+	case "b":
+		return isBrokenLink(e)
+	}
+	return false
+}
+
+// isEmpty reports whether the entry is a zero-byte regular file or a
+// directory with no entries
+func isEmpty(e *entry) bool {
+	info, err := e.lstat()
+	if err != nil {
+		return false
+	}
+	if info.Mode().IsRegular() {
+		return info.Size() == 0
+	}
+	if !info.IsDir() {
+		return false
+	}
+	dir, err := os.Open(e.path)
+	if err != nil {
+		return false
+	}
+	defer dir.Close()
+	_, err = dir.Readdirnames(1)
+	return err == io.EOF
+}
+
+// isBrokenLink reports whether the entry is a symlink whose target is missing
+func isBrokenLink(e *entry) bool {
+	info, err := e.lstat()
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return false
+	}
+	_, err = os.Stat(e.path)
+	return err != nil
+}
+
+// sizeTerm compares the file size in bytes
+type sizeTerm struct {
+	op    string
+	bytes int64
+}
+
+func (t *sizeTerm) eval(e *entry) bool {
+	if e.isDir() {
+		return false
+	}
+	info, err := e.lstat()
+	if err != nil {
+		return false
+	}
+	return compareInt(info.Size(), t.op, t.bytes)
+}
+
+// mtimeTerm compares the modification time, either by age or against a date
+type mtimeTerm struct {
+	op   string
+	age  time.Duration // set for relative values like 7d
+	date time.Time     // set for dates like 2024-01-31
+}
+
+func (t *mtimeTerm) eval(e *entry) bool {
+	info, err := e.lstat()
+	if err != nil {
+		return false
+	}
+	if !t.date.IsZero() {
+		return compareInt(info.ModTime().Unix(), t.op, t.date.Unix())
+	}
+	return compareInt(int64(time.Since(info.ModTime())), t.op, int64(t.age))
+}
+
+// gitTerm matches the git state, like --git
+type gitTerm struct{ filter string }
+
+func (t *gitTerm) eval(e *entry) bool {
+	return git.Match(e.path, t.filter, e.isDir())
+}
+
+// kindTerm matches regular files whose content is of the given kind
+// It reads the file, so it is evaluated after the other terms of an AND
+type kindTerm struct{ kind string }
+
+func (t *kindTerm) eval(e *entry) bool {
+	if e.isDir() {
+		return false
+	}
+	info, err := os.Stat(e.path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return kind.Matches(kind.Detect(e.path), t.kind)
+}
+
+// compareInt applies a comparison operator
+func compareInt(a int64, op string, b int64) bool {
+	switch op {
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	default:
+		return a == b
+	}
+}
+
+// Lexer
+
+type tokenKind int
+
+const (
+	tokTerm tokenKind = iota
+	tokOr
+	tokAnd
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string // term text with quotes removed
+	pos  int
+}
+
+// lexQuery splits a query into tokens
+func lexQuery(text string) ([]token, error) {
+	runes := []rune(text)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, pos: i})
+			i++
+		case r == '|':
+			tokens = append(tokens, token{kind: tokOr, pos: i})
+			i++
+		case r == '&':
+			tokens = append(tokens, token{kind: tokAnd, pos: i})
+			i++
+		case (r == '-' || r == '!') && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]):
+			tokens = append(tokens, token{kind: tokNot, text: string(r), pos: i})
+			i++
+		default:
+			start := i
+			var b strings.Builder
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != ')' {
+				if runes[i] == '"' || runes[i] == '\'' {
+					quote := runes[i]
+					end := i + 1
+					for end < len(runes) && runes[end] != quote {
+						end++
+					}
+					if end >= len(runes) {
+						return nil, &QueryError{Pos: i, Msg: "unterminated quote"}
+					}
+					b.WriteString(string(runes[i+1 : end]))
+					i = end + 1
+					continue
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+
+			word := b.String()
+			switch word {
+			case "OR":
+				tokens = append(tokens, token{kind: tokOr, text: word, pos: start})
+			case "AND":
+				tokens = append(tokens, token{kind: tokAnd, text: word, pos: start})
+			case "NOT":
+				tokens = append(tokens, token{kind: tokNot, text: word, pos: start})
+			default:
+				tokens = append(tokens, token{kind: tokTerm, text: word, pos: start})
+			}
+		}
+	}
+
+	return tokens, nil
+}
+
+// Parser
+
+type queryParser struct {
+	tokens []token
+	pos    int
+	end    int // rune length of the query, for errors at the end
+	opts   *ui.Options
+}
+
+// parseQuery compiles a query into a node tree
+func parseQuery(text string, opts *ui.Options) (node, error) {
+	tokens, err := lexQuery(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens, end: len([]rune(text)), opts: opts}
+	if len(tokens) == 0 {
+		return nil, &QueryError{Pos: 0, Msg: "empty query"}
+	}
+
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		tok := p.tokens[p.pos]
+		if tok.kind == tokRParen {
+			return nil, &QueryError{Pos: tok.pos, Msg: "unexpected ')'"}
+		}
+		return nil, &QueryError{Pos: tok.pos, Msg: "unexpected input"}
+	}
+	return n, nil
+}
+
+func (p *queryParser) peek() *token {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+// errorPos returns the position of the current token, or the end of the query
+func (p *queryParser) errorPos() int {
+	if tok := p.peek(); tok != nil {
+		return tok.pos
+	}
+	return p.end
+}
+
+func (p *queryParser) parseOr() (node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []node{first}
+	for tok := p.peek(); tok != nil && tok.kind == tokOr; tok = p.peek() {
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, next)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &orNode{children: children}, nil
+}
+
+func (p *queryParser) parseAnd() (node, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	children := []node{first}
+	for {
+		tok := p.peek()
+		if tok == nil || tok.kind == tokOr || tok.kind == tokRParen {
+			break
+		}
+		if tok.kind == tokAnd {
+			p.pos++
+		}
+		next, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, next)
+	}
+	return flattenAnd(children), nil
+}
+
+func (p *queryParser) parseUnary() (node, error) {
+	tok := p.peek()
+	if tok == nil {
+		return nil, &QueryError{Pos: p.end, Msg: "expected a term"}
+	}
+
+	switch tok.kind {
+	case tokNot:
+		p.pos++
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{child: child}, nil
+	case tokLParen:
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next := p.peek(); next == nil || next.kind != tokRParen {
+			return nil, &QueryError{Pos: p.errorPos(), Msg: fmt.Sprintf("expected ')' to close '(' at column %d", tok.pos+1)}
+		}
+		p.pos++
+		return inner, nil
+	case tokTerm:
+		p.pos++
+		return p.parseTerm(tok)
+	case tokRParen:
+		return nil, &QueryError{Pos: tok.pos, Msg: "unexpected ')'"}
+	default:
+		return nil, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("expected a term before '%s'", tokenText(tok))}
+	}
+}
+
+// tokenText returns how a token was written
+func tokenText(tok *token) string {
+	switch tok.kind {
+	case tokOr:
+		if tok.text != "" {
+			return tok.text
+		}
+		return "|"
+	case tokAnd:
+		if tok.text != "" {
+			return tok.text
+		}
+		return "&"
+	default:
+		return tok.text
+	}
+}
+
+// parseTerm compiles a single field:value term or bare name pattern
+func (p *queryParser) parseTerm(tok *token) (node, error) {
+	field, value, ok := splitField(tok.text)
+	if !ok {
+		return newNameTerm(tok.text, p.opts), nil
+	}
+	if !isQueryField(field) {
+		return nil, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("unknown field '%s' (use %s)", field, strings.Join(queryFields, ", "))}
+	}
+
+	valuePos := tok.pos + utf8.RuneCountInString(field) + 1
+	if value == "" {
+		return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("missing value after '%s:'", field)}
+	}
+
+	switch field {
+	case "name":
+		return newNameTerm(value, p.opts), nil
+	case "path":
+		return newPathTerm(value, p.opts), nil
+	case "ext":
+		var exts []string
+		for _, ext := range strings.Split(value, ",") {
+			exts = append(exts, strings.ToLower(strings.TrimPrefix(ext, ".")))
+		}
+		return &extTerm{exts: exts}, nil
+	case "type":
+		kind := strings.ToLower(value)
+		switch kind {
+		case "f", "file":
+			return &typeTerm{kind: "f"}, nil
+		case "d", "dir", "directory":
+			return &typeTerm{kind: "d"}, nil
+		case "l", "link", "symlink":
+			return &typeTerm{kind: "l"}, nil
+		case "x", "exec", "executable":
+			return &typeTerm{kind: "x"}, nil
+		case "e", "empty":
+			return &typeTerm{kind: "e"}, nil
+		case "b", "broken":
+			return &typeTerm{kind: "b"}, nil
+		}
+		return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("unknown type '%s' (use f, d, l, x, empty or broken)", value)}
+	case "size":
+		op, rest := splitOperator(value)
+		bytes, err := parseSize(rest)
+		if err != nil {
+			return nil, &QueryError{Pos: valuePos + utf8.RuneCountInString(value) - utf8.RuneCountInString(rest), Msg: err.Error()}
+		}
+		return &sizeTerm{op: op, bytes: bytes}, nil
+	case "mtime":
+		op, rest := splitOperator(value)
+		if date, err := parseDate(rest); err == nil {
+			return &mtimeTerm{op: op, date: date}, nil
+		}
+		age, err := parseAge(rest)
+		if err != nil {
+			return nil, &QueryError{Pos: valuePos + utf8.RuneCountInString(value) - utf8.RuneCountInString(rest), Msg: err.Error()}
+		}
+		return &mtimeTerm{op: op, age: age}, nil
+	case "git":
+		if !git.IsValidFilter(value) {
+			return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("unknown git state '%s' (use %s)", value, strings.Join(git.Filters, ", "))}
+		}
+		return &gitTerm{filter: value}, nil
+	case "kind":
+		value = strings.ToLower(value)
+		if !kind.IsValid(value) {
+			return nil, &QueryError{Pos: valuePos, Msg: fmt.Sprintf("unknown kind '%s' (use %s)", value, kindList())}
+		}
+		return &kindTerm{kind: value}, nil
+	}
+	return nil, &QueryError{Pos: tok.pos, Msg: "unsupported field"}
+}
+
+// newPathTerm compiles a path: term
+func newPathTerm(value string, opts *ui.Options) *pathTerm {
+	ignoreCase := isIgnoreCase(value, opts)
+	if strings.ContainsAny(value, "*?[") {
+		return &pathTerm{text: value, glob: newMatcher(value, ignoreCase)}
+	}
+	value = filepath.ToSlash(value)
+	if ignoreCase {
+		return &pathTerm{text: folder.String(value), fold: true}
+	}
+	return &pathTerm{text: value}
+}
+
+// kindList returns the valid kind: values for error messages
+func kindList() string {
+	names := make([]string, len(kind.Kinds))
+	for i, k := range kind.Kinds {
+		names[i] = string(k)
+	}
+	return strings.Join(names, ", ")
+}
+
+// splitField splits "field:value"; the field must be a plain word
+func splitField(text string) (field, value string, ok bool) {
+	field, value, ok = strings.Cut(text, ":")
+	if !ok || field == "" {
+		return "", "", false
+	}
+	for _, r := range field {
+		if !unicode.IsLetter(r) {
+			return "", "", false
+		}
+	}
+	return strings.ToLower(field), value, true
+}
+
+// isQueryField reports whether field is a known query field
+func isQueryField(field string) bool {
+	for _, f := range queryFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// splitOperator splits a leading comparison operator from a value
+func splitOperator(value string) (op, rest string) {
+	for _, candidate := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, candidate) {
+			return candidate, value[len(candidate):]
+		}
+	}
+	return "=", value
+}
+
+// parseSize parses sizes like 512, 10K, 1.5M or 2G (1024-based, as FormatSize prints them)
+func parseSize(value string) (int64, error) {
+	upper := strings.ToUpper(strings.TrimSuffix(strings.TrimSuffix(value, "B"), "b"))
+	multiplier := int64(1)
+	if upper != "" {
+		switch upper[len(upper)-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			upper = upper[:len(upper)-1]
+		}
+	}
+
+	n, err := strconv.ParseFloat(upper, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size '%s' (e.g. 500K, 10M, 2G)", value)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// ageUnits maps age suffixes to durations
+var ageUnits = map[string]time.Duration{
+	"s":   time.Second,
+	"min": time.Minute,
+	"h":   time.Hour,
+	"d":   24 * time.Hour,
+	"w":   7 * 24 * time.Hour,
+	"mo":  30 * 24 * time.Hour,
+	"y":   365 * 24 * time.Hour,
+}
+
+// parseAge parses ages like 30min, 12h, 7d, 2w, 6mo or 1y
+func parseAge(value string) (time.Duration, error) {
+	i := 0
+	for i < len(value) && (value[i] == '.' || (value[i] >= '0' && value[i] <= '9')) {
+		i++
+	}
+	n, err := strconv.ParseFloat(value[:i], 64)
+	unit, ok := ageUnits[strings.ToLower(value[i:])]
+	if err != nil || !ok {
+		return 0, fmt.Errorf("invalid time '%s' (e.g. 30min, 12h, 7d, 2w or 2024-01-31)", value)
+	}
+	return time.Duration(n * float64(unit)), nil
+}
+
+// parseDate parses dates like 2024-01-31 or RFC 3339 timestamps in local time
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,98 @@
+package search
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ReggieAlbiosA/fcf/internal/ui"
+)
+
+func TestPlainPatternsAreNotQueries(t *testing.T) {
+	patterns := []string{
+		"*.log",
+		"config",
+		"Tom & Jerry*",
+		"a | b.txt",
+		"-rf",
+		"!important",
+		"rock & roll (live).mp3",
+		"notes:2024.txt", // unknown field
+		"size:big*",      // known field, but not a valid query
+		"it's.txt",       // unterminated quote
+		"or and not",     // keywords are upper case only
+	}
+	for _, pattern := range patterns {
+		if IsQuery(pattern) {
+			t.Errorf("IsQuery(%q) = true, want false", pattern)
+		}
+
+		f, err := newFilter(pattern, "/", &ui.Options{})
+		if err != nil {
+			t.Errorf("newFilter(%q): %v", pattern, err)
+			continue
+		}
+		term, ok := f.expr.(*nameTerm)
+		if !ok {
+			t.Errorf("newFilter(%q) compiled to %T, want a name term", pattern, f.expr)
+			continue
+		}
+		if term.pattern != pattern {
+			t.Errorf("newFilter(%q) name pattern = %q", pattern, term.pattern)
+		}
+	}
+}
+
+func TestQueriesAreDetected(t *testing.T) {
+	queries := []string{
+		"name:*.log",
+		"size:>10M",
+		"*.jpg OR *.png",
+		"*.go AND NOT path:vendor",
+		"NOT *.tmp",
+		"(ext:jpg | ext:png) -path:cache",
+	}
+	for _, query := range queries {
+		if !IsQuery(query) {
+			t.Errorf("IsQuery(%q) = false, want true", query)
+		}
+	}
+}
+
+func TestQueryErrorPositions(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+	}{
+		{"size:", 5},             // missing value
+		{"size:>big", 6},         // after the operator
+		{"size:big", 5},          // no operator written
+		{"type:q", 5},            // unknown type
+		{"name:a mtime:<3x", 14}, // bad age, after '<'
+		{"(name:a", 7},           // unclosed paren, at the end
+		{"name:a )", 7},          // stray paren
+		{"name:a OR", 9},         // missing term at the end
+		{"OR name:a", 0},         // missing term before OR
+		{"name:a 'b", 7},         // unterminated quote
+		{"git:dirty", 4},         // unknown git state
+		{"kind:sound", 5},        // unknown kind
+		{"name:é size:x", 12},    // positions count runes, not bytes
+		{"name:日本 size:>x", 14},
+		{"path:é mtime:>é", 14},
+		{"'日本 x' size:<=1q", 14},
+		{"path:src AND (ext:go", 20},    // unclosed paren after AND
+		{"ext:go NOT", 10},              // NOT with nothing to negate
+		{"name:a | | name:b", 9},        // doubled OR
+		{"mtime:>2024-13-01 name:a", 7}, // invalid date and age
+	}
+	for _, tt := range tests {
+		err := ValidateQuery(tt.query)
+		var queryErr *QueryError
+		if !errors.As(err, &queryErr) {
+			t.Errorf("ValidateQuery(%q) = %v, want a QueryError", tt.query, err)
+			continue
+		}
+		if queryErr.Pos != tt.pos {
+			t.Errorf("ValidateQuery(%q) error at %d (%s), want %d", tt.query, queryErr.Pos, queryErr.Msg, tt.pos)
+		}
+	}
+}
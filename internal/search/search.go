@@ -3,19 +3,87 @@ package search
 import (
 	"bufio"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/ReggieAlbiosA/fcf/internal/git"
 	"github.com/ReggieAlbiosA/fcf/internal/input"
+	"github.com/ReggieAlbiosA/fcf/internal/store"
 	"github.com/ReggieAlbiosA/fcf/internal/ui"
+	"github.com/ReggieAlbiosA/fcf/internal/usage"
 )
 
 // SearchResult contains the search results and metadata
 type SearchResult struct {
-	Results []string
-	Stopped bool // true if search was stopped by user
+	Results      *store.Paths   // matches, kept compact and spilled to disk when huge
+	Stopped      bool           // true if search was stopped by user
+	LimitReached bool           // true if search ended after --max-results matches
+	TimedOut     bool           // true if search ended because --timeout passed
+	Errors       []ui.PathError // paths that could not be read
+
+	progress *ui.Progress // status line to keep below streamed results
+	filter   *filter      // conditions the results met, for highlighting
+}
+
+// Spans returns the parts of path that matched the search, for highlighting
+func (r *SearchResult) Spans(path string) []ui.Span {
+	if r.filter == nil {
+		return nil
+	}
+	return r.filter.spans(path)
+}
+
+// Status reports how the search finished
+func (r *SearchResult) Status() ui.SearchStatus {
+	switch {
+	case r.Stopped:
+		return ui.StatusStopped
+	case r.LimitReached:
+		return ui.StatusLimitReached
+	case r.TimedOut:
+		return ui.StatusTimedOut
+	default:
+		return ui.StatusComplete
+	}
+}
+
+// addResult records a match and streams it to the display
+// Returns false once the --max-results limit has been reached
+func (r *SearchResult) addResult(path string, opts *ui.Options) bool {
+	r.Results.Add(path)
+	count := r.Results.Len()
+
+	// Display result in real-time (streaming); a tree is drawn at the end
+	if displayed(count, opts) {
+		r.progress.Print(func() { ui.ShowResult(path, count, r.Spans(path)) })
+	}
+
+	if opts.MaxResults > 0 && count >= opts.MaxResults {
+		r.LimitReached = true
+		return false
+	}
+	return true
+}
+
+// displayed reports whether match number count is streamed. --max-display
+// does not cut JSON output, so its results agree with the summary count
+func displayed(count int, opts *ui.Options) bool {
+	if drawsTree(opts) {
+		return false
+	}
+	return opts.JSON || opts.NDJSON || opts.MaxDisplay == 0 || count <= opts.MaxDisplay
+}
+
+// drawsTree reports whether results are drawn as a tree once the search
+// ends, since the tree needs every match and count, instead of streamed
+func drawsTree(opts *ui.Options) bool {
+	return opts.Tree && !ui.Scripted()
 }
 
 // getFdCommand returns the fd command name if available
@@ -36,82 +104,213 @@ func HasFd() bool {
 }
 
 // SearchWithFd uses fd for fast parallel search
-func SearchWithFd(pattern, searchPath string, opts *ui.Options, stopChan <-chan struct{}) (*SearchResult, error) {
-	args := []string{"--color", "never", "--hidden", "--no-ignore"}
-
-	// Type filter
-	if opts.Type == "f" {
-		args = append(args, "-t", "f")
-	} else if opts.Type == "d" {
-		args = append(args, "-t", "d")
+func SearchWithFd(pattern, searchPath string, opts *ui.Options, stopChan <-chan struct{}, progress *ui.Progress) (*SearchResult, error) {
+	f, err := newFilter(pattern, searchPath, opts)
+	if err != nil {
+		return nil, err
 	}
+	f.pushToFd()
 
-	// Case sensitivity
-	if opts.IgnoreCase {
-		args = append(args, "-i")
-	} else {
-		args = append(args, "-s")
+	result := &SearchResult{
+		Results:  store.New(),
+		Stopped:  false,
+		progress: progress,
+		filter:   f,
 	}
 
-	// Glob pattern and path
-	args = append(args, "-g", pattern, searchPath)
+	// fd's parallel output order changes from run to run
+	if opts.Stable {
+		return result, searchFdStable(f, searchPath, opts, stopChan, progress, result)
+	}
 
-	cmd := exec.Command(getFdCommand(), args...)
-	stdout, err := cmd.StdoutPipe()
+	p, err := startFd(f.fdArgs(searchPath))
 	if err != nil {
 		return nil, err
 	}
 
-	if err := cmd.Start(); err != nil {
-		return nil, err
-	}
+	// Kill fd as soon as a stop is requested, even while it is not printing anything
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stopChan:
+			p.cmd.Process.Kill()
+		case <-done:
+		}
+	}()
 
-	result := &SearchResult{
-		Results: []string{},
-		Stopped: false,
-	}
-	scanner := bufio.NewScanner(stdout)
-	count := 0
+	scanner := bufio.NewScanner(p.stdout)
+	killed := false
 
 	for scanner.Scan() {
 		// Check for stop signal
 		select {
 		case <-stopChan:
-			cmd.Process.Kill()
+			p.cmd.Process.Kill()
+			killed = true
 			result.Stopped = true
-			return result, nil
 		default:
 		}
+		if killed {
+			break
+		}
 
-		line := scanner.Text()
+		line := fdLine(scanner.Text())
 		if line == "" {
 			continue
 		}
+		progress.AddEntry(line)
 
-		count++
-		result.Results = append(result.Results, line)
+		// Conditions fd could not apply itself
+		if !f.match(line, nil) {
+			continue
+		}
 
-		// Display result in real-time (streaming)
-		if opts.MaxDisplay == 0 || count <= opts.MaxDisplay {
-			ui.ShowResult(line, count)
+		if !result.addResult(line, opts) {
+			// Limit reached, no need to let fd finish
+			p.cmd.Process.Kill()
+			killed = true
+			break
 		}
 	}
 
-	cmd.Wait()
+	waitErr := p.wait()
+
+	// An unreadable root is fatal, as it is for the walkers, not a path error
+	if err := rootError(p.pathErrors, searchPath); err != nil {
+		return result, err
+	}
+	result.Errors = p.pathErrors
+
+	// fd may have been killed before printing anything
+	select {
+	case <-stopChan:
+		killed = true
+		result.Stopped = !result.LimitReached
+	default:
+	}
+
+	if !killed {
+		return result, waitErr
+	}
 	return result, nil
 }
 
+// fdArgs returns fd's arguments to search dir with the conditions pushed
+// to fd; extra options go before the pattern
+func (f *filter) fdArgs(dir string, extra ...string) []string {
+	args := []string{"--color", "never", "--hidden", "--no-ignore", "--show-errors"}
+
+	// Type filter
+	if f.fdType == "f" {
+		args = append(args, "-t", "f")
+	} else if f.fdType == "d" {
+		args = append(args, "-t", "d")
+	}
+
+	// Case sensitivity; case-insensitive names are rechecked in Go
+	if f.fdIgnoreCase {
+		args = append(args, "-i")
+	} else {
+		args = append(args, "-s")
+	}
+	args = append(args, extra...)
+
+	// Pattern and path
+	// Accented patterns go through a regex so decomposed (NFD) names match too
+	if needsNormalization(f.fdGlob) {
+		return append(args, "--", globToFdRegex(f.fdGlob), dir)
+	}
+	return append(args, "-g", f.fdGlob, dir)
+}
+
+// fdProcess is a running fd and the error output it has written
+type fdProcess struct {
+	cmd        *exec.Cmd
+	stdout     io.ReadCloser
+	stderrDone chan struct{}
+	pathErrors []ui.PathError
+	messages   []string
+}
+
+// startFd runs fd and starts collecting its error output, which must be
+// drained before the process can be waited for
+func startFd(args []string) (*fdProcess, error) {
+	cmd := exec.Command(getFdCommand(), args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &fdProcess{cmd: cmd, stdout: stdout, stderrDone: make(chan struct{})}
+	go func() {
+		defer close(p.stderrDone)
+		p.pathErrors, p.messages = readFdErrors(stderr)
+	}()
+	return p, nil
+}
+
+// wait drains what is left of fd's output, so it is not blocked writing to
+// a full pipe, and waits for it to exit. Exiting because of unreadable paths
+// is not an error; they are in pathErrors
+func (p *fdProcess) wait() error {
+	io.Copy(io.Discard, p.stdout)
+	<-p.stderrDone
+	err := p.cmd.Wait()
+	if err == nil || fdExitedWithPathErrors(err, p.pathErrors) {
+		return nil
+	}
+	if len(p.messages) > 0 {
+		return fmt.Errorf("fd failed: %v: %s", err, strings.Join(p.messages, "; "))
+	}
+	return fmt.Errorf("fd failed: %v", err)
+}
+
+// fdLine returns the path fd printed on a line
+// Newer fd versions end directories with a separator
+func fdLine(line string) string {
+	return strings.TrimSuffix(line, string(filepath.Separator))
+}
+
+// rootError returns the error for searchPath itself if fd reported one
+func rootError(pathErrors []ui.PathError, searchPath string) error {
+	for _, e := range pathErrors {
+		if filepath.Clean(e.Path) == searchPath {
+			return &fs.PathError{Op: "open", Path: searchPath, Err: e.Err}
+		}
+	}
+	return nil
+}
+
 // SearchWithWalk uses filepath.WalkDir as fallback
-func SearchWithWalk(pattern, searchPath string, opts *ui.Options, stopChan <-chan struct{}) (*SearchResult, error) {
+func SearchWithWalk(pattern, searchPath string, opts *ui.Options, stopChan <-chan struct{}, progress *ui.Progress) (*SearchResult, error) {
 	result := &SearchResult{
-		Results: []string{},
-		Stopped: false,
+		Results:  store.New(),
+		Stopped:  false,
+		progress: progress,
 	}
-	count := 0
+	f, err := newFilter(pattern, searchPath, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.filter = f
 
-	err := filepath.WalkDir(searchPath, func(path string, d os.DirEntry, err error) error {
+	err = filepath.WalkDir(searchPath, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
-			return nil // Skip errors, continue walking
+			// The root itself is unreadable: nothing to walk
+			if path == searchPath {
+				return err
+			}
+			// Record the error and keep walking
+			result.Errors = append(result.Errors, newPathError(path, d, err))
+			return nil
 		}
 
 		// Check for stop signal
@@ -122,32 +321,28 @@ func SearchWithWalk(pattern, searchPath string, opts *ui.Options, stopChan <-cha
 		default:
 		}
 
+		if d.IsDir() {
+			progress.AddDir(path)
+		}
+
 		// Skip the root directory itself
 		if path == searchPath {
 			return nil
 		}
+		progress.AddEntry(path)
 
-		// Type filter
-		if opts.Type == "f" && d.IsDir() {
-			return nil
-		}
-		if opts.Type == "d" && !d.IsDir() {
-			return nil
+		// Excluded directories are not descended into
+		if d.IsDir() && f.prune(path, d) {
+			return filepath.SkipDir
 		}
 
-		// Pattern matching
-		name := d.Name()
-		matched := matchPattern(name, pattern, opts.IgnoreCase)
-		if !matched {
+		// Pattern, type, git and query conditions
+		if !f.match(path, d) {
 			return nil
 		}
 
-		count++
-		result.Results = append(result.Results, path)
-
-		// Display result in real-time (streaming)
-		if opts.MaxDisplay == 0 || count <= opts.MaxDisplay {
-			ui.ShowResult(path, count)
+		if !result.addResult(path, opts) {
+			return filepath.SkipAll
 		}
 
 		return nil
@@ -156,64 +351,210 @@ func SearchWithWalk(pattern, searchPath string, opts *ui.Options, stopChan <-cha
 	return result, err
 }
 
-// matchPattern checks if name matches the glob pattern
-func matchPattern(name, pattern string, ignoreCase bool) bool {
-	if ignoreCase {
-		name = strings.ToLower(name)
-		pattern = strings.ToLower(pattern)
+// SearchWithBFS walks the tree level by level, so shallow matches come first
+// Only directories wait in the queue, so memory grows with the number of
+// directories on a level rather than with the number of files
+func SearchWithBFS(pattern, searchPath string, opts *ui.Options, stopChan <-chan struct{}, progress *ui.Progress) (*SearchResult, error) {
+	result := &SearchResult{
+		Results:  store.New(),
+		Stopped:  false,
+		progress: progress,
+	}
+	f, err := newFilter(pattern, searchPath, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.filter = f
+
+	queue := []string{searchPath}
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+
+		// Check for stop signal
+		select {
+		case <-stopChan:
+			result.Stopped = true
+			return result, nil
+		default:
+		}
+
+		progress.AddDir(dir)
+
+		// Like WalkDir, entries read before an error are still searched
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			// The root itself is unreadable: nothing to walk
+			if dir == searchPath {
+				return result, err
+			}
+			result.Errors = append(result.Errors, ui.PathError{Path: dir, Kind: ui.ClassifyError(err), Dir: true, Err: err})
+		}
+
+		for _, d := range entries {
+			path := filepath.Join(dir, d.Name())
+			progress.AddEntry(path)
+
+			// Excluded directories are not descended into
+			if d.IsDir() {
+				if f.prune(path, d) {
+					continue
+				}
+				queue = append(queue, path)
+			}
+
+			// Pattern, type, git and query conditions
+			if !f.match(path, d) {
+				continue
+			}
+
+			if !result.addResult(path, opts) {
+				return result, nil
+			}
+		}
 	}
 
-	matched, err := filepath.Match(pattern, name)
+	return result, nil
+}
+
+// checkReadable reports an error if the entries of dir cannot be listed
+func checkReadable(dir string) error {
+	f, err := os.Open(dir)
 	if err != nil {
-		return false
+		return err
 	}
-	return matched
+	defer f.Close()
+	if _, err := f.ReadDir(1); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
 }
 
-// search performs the search using fd or fallback
-func Search(pattern, searchPath string) ([]string, bool) {
+// Search performs the search using fd or fallback and reports whether fd
+// was available; the caller closes the returned store when done with it
+func Search(pattern, searchPath string) (*store.Paths, bool) {
 	result, _ := SearchWithStop(pattern, searchPath)
 	return result.Results, HasFd()
 }
 
 // SearchWithStop performs the search with ability to stop via 's' key
-func SearchWithStop(pattern, searchPath string) (*SearchResult, bool) {
+// or when the --timeout deadline passes
+// Unreadable paths are collected in the result; an unreachable root or a
+// failing fd is returned as an error
+func SearchWithStop(pattern, searchPath string) (*SearchResult, error) {
 	// Resolve search path
 	absPath, err := filepath.Abs(searchPath)
 	if err != nil {
 		absPath = searchPath
 	}
 
-	usingFd := HasFd()
+	// Make sure the root can be searched before printing anything
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return &SearchResult{Results: store.New()}, err
+	}
+	if !info.IsDir() {
+		return &SearchResult{Results: store.New()}, fmt.Errorf("%s is not a directory", absPath)
+	}
+	if err := checkReadable(absPath); err != nil {
+		return &SearchResult{Results: store.New()}, err
+	}
+
+	// Start from fresh repository state and directory sizes on every search
+	git.Reset()
+	usage.Reset()
+	if ui.Opts.Git != "" && !git.Available() {
+		return &SearchResult{Results: store.New()}, fmt.Errorf("--git needs 'git' in PATH")
+	}
+
+	ui.SetSearchRoot(absPath)
+
+	// fd has no breadth-first mode
+	usingFd := HasFd() && !ui.Opts.BFS
 	ui.ShowSearchInfo(absPath, pattern, usingFd)
 
-	fmt.Printf("%s %s  %s\n\n",
-		ui.Colors.Bold("Results:"),
-		ui.Colors.Dim("(streaming in real-time...)"),
-		ui.Colors.Yellow("[press 's' to stop]"))
+	// A pattern that looks like a query but does not parse is searched as a name
+	if LooksLikeQuery(pattern) {
+		if err := ValidateQuery(pattern); err != nil {
+			fmt.Fprintf(os.Stderr, "%s not a valid query (%v), matching it as a name pattern\n", ui.Colors.Yellow("Warning:"), err)
+		}
+	}
+
+	tree := drawsTree(&ui.Opts)
+	streaming := "(streaming in real-time...)"
+	if tree {
+		streaming = "(tree, shown when the search finishes...)"
+	}
+	if !ui.Scripted() {
+		fmt.Printf("%s %s  %s\n\n",
+			ui.Colors.Bold("Results:"),
+			ui.Colors.Dim(streaming),
+			ui.Colors.Yellow("[press 's' to stop]"))
+	}
 
 	// Set up stop channel and key listener
+	// The 's' key and the timeout share the same stop path; the first one wins
 	stopChan := make(chan struct{})
+	var stopOnce sync.Once
+	var stopReason ui.SearchStatus
+	stop := func(reason ui.SearchStatus) {
+		stopOnce.Do(func() {
+			stopReason = reason
+			close(stopChan)
+		})
+	}
+
+	// Directory sizes come from one scan of the root, run alongside the search
+	if ui.Opts.DirSizes {
+		usage.Prescan(absPath, stopChan)
+	}
+
+	// Scripted output is stopped with Ctrl+C; raw mode would garble stderr
 	keyChan := make(chan string, 10)
-	stopListener := input.StartKeyListener(keyChan)
-	defer stopListener()
+	if !ui.Scripted() {
+		stopListener := input.StartKeyListener(keyChan)
+		defer stopListener()
+	}
 
 	// Goroutine to handle 's' key press
 	go func() {
 		for key := range keyChan {
 			if strings.ToLower(key) == "s" {
-				close(stopChan)
+				stop(ui.StatusStopped)
 				return
 			}
 		}
 	}()
 
+	if ui.Opts.Timeout > 0 {
+		timer := time.AfterFunc(ui.Opts.Timeout, func() { stop(ui.StatusTimedOut) })
+		defer timer.Stop()
+	}
+
+	progress := ui.StartProgress(usingFd)
+
 	var result *SearchResult
 	if usingFd {
-		result, _ = SearchWithFd(pattern, absPath, &ui.Opts, stopChan)
+		result, err = SearchWithFd(pattern, absPath, &ui.Opts, stopChan, progress)
+	} else if ui.Opts.BFS {
+		result, err = SearchWithBFS(pattern, absPath, &ui.Opts, stopChan, progress)
 	} else {
-		result, _ = SearchWithWalk(pattern, absPath, &ui.Opts, stopChan)
+		result, err = SearchWithWalk(pattern, absPath, &ui.Opts, stopChan, progress)
+	}
+	progress.Stop()
+	if result == nil {
+		result = &SearchResult{Results: store.New()}
+	}
+	if tree {
+		ui.ShowTree(absPath, result.Results, result.Spans)
 	}
 
-	return result, usingFd
+	// Settle the stop reason so a late timer or key press cannot race with it
+	stop(ui.StatusComplete)
+	if result.Stopped && stopReason == ui.StatusTimedOut {
+		result.Stopped = false
+		result.TimedOut = true
+	}
+
+	return result, err
 }
@@ -0,0 +1,254 @@
+package store
+
+import (
+	"encoding/binary"
+	"os"
+	"sync"
+)
+
+// blockSize is the number of paths per block; each block starts with a full
+// path, so a lookup decodes at most this many entries
+const blockSize = 128
+
+// memoryBudget is how many bytes of encoded blocks stay in memory before
+// later blocks are written to a temporary file
+const memoryBudget = 16 << 20
+
+// Paths is an append-only list of paths that keeps memory flat for huge
+// result sets. Paths in a block are front-coded against the previous one,
+// which suits search results sharing long directory prefixes, and blocks
+// beyond the memory budget are spilled to disk
+// Reading methods and Close are safe to call on a nil *Paths, which is empty
+type Paths struct {
+	mu    sync.Mutex
+	count int
+
+	blocks  []block
+	inMem   int      // bytes of encoded blocks held in memory
+	spill   *os.File // nil until the budget is exceeded
+	spillAt int64    // end of the spill file
+
+	// Block being filled
+	current []byte
+	last    string
+	inBlock int
+
+	// Last block decoded by At, for sequential access
+	cacheIndex int
+	cache      []string
+}
+
+// block is a sealed block, either in memory or at an offset of the spill file
+type block struct {
+	data   []byte
+	offset int64
+	length int
+}
+
+// New returns an empty store
+func New() *Paths {
+	return &Paths{cacheIndex: -1}
+}
+
+// FromSlice returns a store holding paths
+func FromSlice(paths []string) *Paths {
+	p := New()
+	for _, path := range paths {
+		p.Add(path)
+	}
+	return p
+}
+
+// Add appends a path
+func (p *Paths) Add(path string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	shared := 0
+	if p.inBlock > 0 {
+		shared = commonPrefix(p.last, path)
+	}
+	p.current = binary.AppendUvarint(p.current, uint64(shared))
+	p.current = binary.AppendUvarint(p.current, uint64(len(path)-shared))
+	p.current = append(p.current, path[shared:]...)
+
+	p.last = path
+	p.inBlock++
+	if p.cacheIndex == len(p.blocks) {
+		p.cacheIndex = -1 // the cached block just grew
+	}
+	p.count++
+	if p.inBlock == blockSize {
+		p.seal()
+	}
+}
+
+// Len returns the number of paths
+func (p *Paths) Len() int {
+	if p == nil {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.count
+}
+
+// At returns the i-th path, counting from zero, or "" if there is none
+func (p *Paths) At(i int) string {
+	if p == nil {
+		return ""
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if i < 0 || i >= p.count {
+		return ""
+	}
+
+	b := i / blockSize
+	if p.cacheIndex != b {
+		p.cache = decode(p.blockData(b))
+		p.cacheIndex = b
+	}
+	if i%blockSize >= len(p.cache) {
+		return "" // the spill file could not be read
+	}
+	return p.cache[i%blockSize]
+}
+
+// All returns every path as a slice
+func (p *Paths) All() []string {
+	n := p.Len()
+	paths := make([]string, n)
+	for i := range paths {
+		paths[i] = p.At(i)
+	}
+	return paths
+}
+
+// Close removes the spill file, if any
+func (p *Paths) Close() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.spill != nil {
+		name := p.spill.Name()
+		p.spill.Close()
+		os.Remove(name)
+		p.spill = nil
+
+		spilledMu.Lock()
+		delete(spilled, p)
+		spilledMu.Unlock()
+	}
+}
+
+// Stores with a spill file, for CloseAll
+var (
+	spilledMu sync.Mutex
+	spilled   = map[*Paths]bool{}
+)
+
+// CloseAll removes the spill files of every store not closed yet, for
+// when fcf is interrupted before the stores are done with
+func CloseAll() {
+	spilledMu.Lock()
+	open := make([]*Paths, 0, len(spilled))
+	for p := range spilled {
+		open = append(open, p)
+	}
+	spilledMu.Unlock()
+
+	for _, p := range open {
+		p.Close()
+	}
+}
+
+// seal closes the current block, spilling it if memory is used up; callers hold p.mu
+func (p *Paths) seal() {
+	data := p.current
+	p.current = nil
+	p.inBlock = 0
+
+	if p.inMem+len(data) > memoryBudget && p.openSpill() {
+		if _, err := p.spill.WriteAt(data, p.spillAt); err == nil {
+			p.blocks = append(p.blocks, block{offset: p.spillAt, length: len(data)})
+			p.spillAt += int64(len(data))
+			return
+		}
+	}
+
+	// Within budget, or the disk is not usable: keep it in memory
+	p.blocks = append(p.blocks, block{data: data})
+	p.inMem += len(data)
+}
+
+// openSpill creates the spill file on first use; callers hold p.mu
+func (p *Paths) openSpill() bool {
+	if p.spill != nil {
+		return true
+	}
+	f, err := os.CreateTemp("", "fcf-results-*")
+	if err != nil {
+		return false
+	}
+	p.spill = f
+
+	spilledMu.Lock()
+	spilled[p] = true
+	spilledMu.Unlock()
+	return true
+}
+
+// blockData returns the encoded bytes of block b; callers hold p.mu
+func (p *Paths) blockData(b int) []byte {
+	if b == len(p.blocks) {
+		return p.current
+	}
+	blk := p.blocks[b]
+	if blk.data != nil {
+		return blk.data
+	}
+	if p.spill == nil {
+		return nil
+	}
+	data := make([]byte, blk.length)
+	if _, err := p.spill.ReadAt(data, blk.offset); err != nil {
+		return nil
+	}
+	return data
+}
+
+// decode expands a front-coded block into its paths
+func decode(data []byte) []string {
+	var paths []string
+	prev := ""
+	for len(data) > 0 {
+		shared, n := binary.Uvarint(data)
+		data = data[n:]
+		length, n := binary.Uvarint(data)
+		data = data[n:]
+
+		path := prev[:shared] + string(data[:length])
+		data = data[length:]
+
+		paths = append(paths, path)
+		prev = path
+	}
+	return paths
+}
+
+// commonPrefix returns the length of the longest common prefix of a and b
+func commonPrefix(a, b string) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
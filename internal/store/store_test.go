@@ -0,0 +1,150 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+	}{
+		{"empty", nil},
+		{"one", []string{"/a"}},
+		{"shared prefixes", []string{"/src/app/main.go", "/src/app/main_test.go", "/src/lib/x.go", "/src"}},
+		{"prefix of the previous path", []string{"/a/b/c", "/a/b", "/a", ""}},
+		{"previous path is a prefix", []string{"", "/a", "/a/b", "/a/b/c"}},
+		{"duplicates", []string{"/x", "/x", "/x"}},
+		{"multi-byte runes split by the shared prefix", []string{"/é", "/è", "/日本", "/日本語", "/日"}},
+		{"block minus one", numbered("/b/", blockSize-1)},
+		{"exactly one block", numbered("/b/", blockSize)},
+		{"block plus one", numbered("/b/", blockSize+1)},
+		{"several blocks", numbered("/very/long/shared/directory/prefix/", 5*blockSize+17)},
+	}
+	for _, tt := range tests {
+		p := FromSlice(tt.paths)
+		checkPaths(t, tt.name, p, tt.paths)
+		p.Close()
+	}
+}
+
+// spillPaths returns paths with little shared prefix, so the encoded
+// blocks pass the memory budget
+func spillPaths() []string {
+	var paths []string
+	size := 0
+	for i := 0; size < memoryBudget+memoryBudget/4; i++ {
+		path := fmt.Sprintf("/%08x/%s", i, strings.Repeat(string(rune('a'+i%26)), 60+i%7))
+		paths = append(paths, path)
+		size += len(path)
+	}
+	return paths
+}
+
+func TestSpill(t *testing.T) {
+	paths := spillPaths()
+	p := FromSlice(paths)
+	defer p.Close()
+	if p.spill == nil {
+		t.Fatalf("%d paths did not spill to disk", len(paths))
+	}
+	if p.inMem > memoryBudget {
+		t.Errorf("%d bytes kept in memory, budget is %d", p.inMem, memoryBudget)
+	}
+	checkPaths(t, "spilled", p, paths)
+
+	// Adding after a spill, and reading blocks out of order
+	p.Add("/after/spill")
+	paths = append(paths, "/after/spill")
+	for _, i := range []int{len(paths) - 1, 0, len(paths) / 2, blockSize, len(paths) - blockSize - 1, 1} {
+		if got := p.At(i); got != paths[i] {
+			t.Errorf("At(%d) = %q, want %q", i, got, paths[i])
+		}
+	}
+
+	name := p.spill.Name()
+	p.Close()
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("spill file %s left behind after Close: %v", name, err)
+	}
+}
+
+func TestCloseAll(t *testing.T) {
+	p := FromSlice(spillPaths())
+	if p.spill == nil {
+		t.Fatal("paths did not spill to disk")
+	}
+	name := p.spill.Name()
+
+	CloseAll()
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("spill file %s left behind after CloseAll: %v", name, err)
+	}
+	if len(spilled) != 0 {
+		t.Errorf("%d stores still listed after CloseAll", len(spilled))
+	}
+	p.Close() // closing again is a no-op
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want []string
+	}{
+		{"empty", nil, nil},
+		{"full path", []byte{0, 3, '/', 'a', 'b'}, []string{"/ab"}},
+		{"shared prefix", []byte{0, 3, '/', 'a', 'b', 2, 1, 'c'}, []string{"/ab", "/ac"}},
+		{"whole previous path", []byte{0, 2, '/', 'a', 2, 2, '/', 'b'}, []string{"/a", "/a/b"}},
+		{"shorter than the previous path", []byte{0, 4, '/', 'a', '/', 'b', 2, 0}, []string{"/a/b", "/a"}},
+	}
+	for _, tt := range tests {
+		if got := decode(tt.data); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: decode(%v) = %q, want %q", tt.name, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestOutOfRange(t *testing.T) {
+	p := FromSlice([]string{"/a", "/b"})
+	for _, i := range []int{-1, 2, 1000} {
+		if got := p.At(i); got != "" {
+			t.Errorf("At(%d) = %q, want \"\"", i, got)
+		}
+	}
+
+	var empty *Paths
+	if empty.Len() != 0 || empty.At(0) != "" || len(empty.All()) != 0 {
+		t.Errorf("a nil *Paths is not empty")
+	}
+	empty.Close()
+}
+
+// numbered returns n paths below dir
+func numbered(dir string, n int) []string {
+	paths := make([]string, n)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("%s%d/file.txt", dir, i)
+	}
+	return paths
+}
+
+// checkPaths compares every path in p, read in order, with want
+func checkPaths(t *testing.T, name string, p *Paths, want []string) {
+	t.Helper()
+	if p.Len() != len(want) {
+		t.Errorf("%s: Len() = %d, want %d", name, p.Len(), len(want))
+		return
+	}
+	got := p.All()
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s: path %d = %q, want %q", name, i, got[i], want[i])
+			return
+		}
+	}
+}
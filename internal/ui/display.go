@@ -4,27 +4,91 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/mattn/go-isatty"
 
+	"github.com/ReggieAlbiosA/fcf/internal/config"
+	"github.com/ReggieAlbiosA/fcf/internal/git"
+	"github.com/ReggieAlbiosA/fcf/internal/icons"
 	"github.com/ReggieAlbiosA/fcf/internal/platform"
+	"github.com/ReggieAlbiosA/fcf/internal/usage"
 )
 
 // Options holds the command-line options
 type Options struct {
-	Pattern    string
-	Path       string
-	IgnoreCase bool
-	Type       string
-	ShowSize   bool
-	MaxDisplay int
-	Help       bool
+	Pattern       string
+	Path          string
+	IgnoreCase    bool
+	CaseSensitive bool
+	SmartCase     bool
+	Type          string
+	ShowSize      bool
+	DirSizes      bool // show recursive sizes for directories
+	MaxDisplay    int
+	MaxResults    int
+	BFS           bool     // breadth-first walk, shallow matches first
+	Stable        bool     // same result order on every run, for both backends
+	Tree          bool     // results drawn as a tree after the search
+	Long          bool     // ls -l style columns before each result
+	Relative      Relative // --relative: paths relative to the search root or cwd
+	Hyperlink     bool     // results are OSC 8 links to their files
+	NoPicker      bool     // number prompt instead of the full-screen picker
+	Timeout       time.Duration
+	ShowErrors    bool
+	Git           string // --git filter: tracked, modified, untracked, staged or ignored
+	GitStatus     bool
+	Empty         bool   // only empty files and directories
+	BrokenLinks   bool   // only symlinks whose target is missing
+	Kind          string // --kind filter: image, video, audio, archive, pdf, text, binary, elf or script
+	Exec          string // --exec template, run once per result
+	ExecBatch     string // --exec-batch template, run with all results
+	Jobs          int    // parallel --exec commands
+	CopyTo        string // --copy-to destination directory
+	MoveTo        string // --move-to destination directory
+	Delete        bool
+	Conflict      string // skip, overwrite or rename existing targets
+	DryRun        bool
+	Color         string // --color: auto, always or never
+	Icons         string // --icons: emoji, nerd, ascii or none
+	Plain         bool   // bare paths, one per line
+	Print0        bool   // bare paths separated by NUL
+	Format        string // --format template
+	CSV           bool   // CSV rows with a header
+	TSV           bool   // TSV rows with a header
+	JSON          bool   // one JSON document with results and summary
+	NDJSON        bool   // one JSON object per result, streamed
+	Help          bool
 }
 
 // Opts holds the global command-line options
 var Opts Options
 
+// SearchStatus describes how a search finished
+type SearchStatus int
+
+const (
+	StatusComplete     SearchStatus = iota // searched the whole tree
+	StatusStopped                          // stopped by the user with 's'
+	StatusLimitReached                     // stopped after --max-results matches
+	StatusTimedOut                         // stopped when --timeout passed
+)
+
+// String returns the status name used in JSON output
+func (s SearchStatus) String() string {
+	switch s {
+	case StatusStopped:
+		return "stopped"
+	case StatusLimitReached:
+		return "limit_reached"
+	case StatusTimedOut:
+		return "timed_out"
+	default:
+		return "complete"
+	}
+}
+
 // ColorFuncs holds color functions for output
 type ColorFuncs struct {
 	Red     func(format string, a ...interface{}) string
@@ -35,31 +99,26 @@ type ColorFuncs struct {
 	Magenta func(format string, a ...interface{}) string
 	Bold    func(format string, a ...interface{}) string
 	Dim     func(format string, a ...interface{}) string
+
+	// Roles, which the theme file can restyle
+	Match     func(format string, a ...interface{}) string // matched part of a result
+	Directory func(format string, a ...interface{}) string // directory results LS_COLORS does not color
+	Header    func(format string, a ...interface{}) string // the fcf banner
+	Prompt    func(format string, a ...interface{}) string // questions waiting for input
+	Summary   func(format string, a ...interface{}) string // match counts and totals
+	Error     func(format string, a ...interface{}) string // error labels
 }
 
 // Colors is the global instance of ColorFuncs
 var Colors ColorFuncs
 
-// InitColors initializes color functions based on terminal support
+// InitColors initializes color functions from --color, NO_COLOR and
+// CLICOLOR_FORCE, then applies the theme file and LS_COLORS
 func InitColors() {
-	isTerm := isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+	enabled := colorEnabled()
+	resultColors = nil
 
-	if isTerm {
-		Colors = ColorFuncs{
-			Red:     color.New(color.FgRed).SprintfFunc(),
-			Green:   color.New(color.FgGreen).SprintfFunc(),
-			Yellow:  color.New(color.FgYellow, color.Bold).SprintfFunc(),
-			Blue:    color.New(color.FgBlue).SprintfFunc(),
-			Cyan:    color.New(color.FgCyan).SprintfFunc(),
-			Magenta: color.New(color.FgMagenta).SprintfFunc(),
-			Bold:    color.New(color.Bold).SprintfFunc(),
-			Dim:     color.New(color.Faint).SprintfFunc(),
-		}
-	} else {
-		// No colors when not a terminal
-		noColor := func(format string, a ...interface{}) string {
-			return fmt.Sprintf(format, a...)
-		}
+	if !enabled {
 		Colors = ColorFuncs{
 			Red:     noColor,
 			Green:   noColor,
@@ -69,8 +128,47 @@ func InitColors() {
 			Magenta: noColor,
 			Bold:    noColor,
 			Dim:     noColor,
+
+			Match:     noColor,
+			Directory: noColor,
+			Header:    noColor,
+			Prompt:    noColor,
+			Summary:   noColor,
+			Error:     noColor,
 		}
+		return
+	}
+
+	Colors = ColorFuncs{
+		Red:     styleFunc(color.FgRed),
+		Green:   styleFunc(color.FgGreen),
+		Yellow:  styleFunc(color.FgYellow, color.Bold),
+		Blue:    styleFunc(color.FgBlue),
+		Cyan:    styleFunc(color.FgCyan),
+		Magenta: styleFunc(color.FgMagenta),
+		Bold:    styleFunc(color.Bold),
+		Dim:     styleFunc(color.Faint),
+
+		Match:     styleFunc(color.Bold, color.Underline),
+		Directory: styleFunc(color.FgBlue),
+		Header:    styleFunc(color.Bold, color.FgCyan),
+		Prompt:    styleFunc(color.FgCyan),
+		Summary:   styleFunc(color.FgGreen),
+		Error:     styleFunc(color.FgRed),
 	}
+	applyTheme(&Colors, config.ThemePath())
+	resultColors = parseLSColors(os.Getenv("LS_COLORS"))
+}
+
+// Scripted reports whether output is meant for another program, in which
+// case the header, search info, progress, summary and prompts are left out
+func Scripted() bool {
+	return Opts.Plain || Opts.Print0 || Opts.JSON || Opts.NDJSON || outputFormat != nil
+}
+
+// IsTerminal reports whether stdout is a terminal
+func IsTerminal() bool {
+	return isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
 }
 
 // clearScreen clears the terminal screen
@@ -80,57 +178,131 @@ func clearScreen() {
 
 // showHeader displays the FCF header
 func ShowHeader() {
+	if Scripted() {
+		return
+	}
 	clearScreen()
-	cyan := Colors.Cyan
-	bold := Colors.Bold
-	fmt.Println(bold(cyan("╔════════════════════════════════════════╗")))
-	fmt.Println(bold(cyan("║")) + "   " + bold("fcf") + " - Find File or Folder          " + bold(cyan("║")))
-	fmt.Println(bold(cyan("╚════════════════════════════════════════╝")))
+	fmt.Println(Colors.Header("╔════════════════════════════════════════╗"))
+	fmt.Println(Colors.Header("║") + "   " + Colors.Bold("fcf") + " - Find File or Folder          " + Colors.Header("║"))
+	fmt.Println(Colors.Header("╚════════════════════════════════════════╝"))
 	fmt.Println()
 }
 
 // showResult displays a single search result with appropriate icon and color
-func ShowResult(filePath string, count int) {
+func ShowResult(filePath string, count int, spans []Span) {
+	if Opts.JSON || Opts.NDJSON {
+		showJSONResult(filePath, count, spans)
+		return
+	}
+	if outputFormat != nil {
+		showFormattedResult(filePath, count)
+		return
+	}
+	if Opts.Print0 {
+		fmt.Print(relativePath(filePath) + "\x00")
+		return
+	}
+	if Opts.Plain {
+		fmt.Println(relativePath(filePath))
+		return
+	}
+
+	line := resultLine(filePath, DisplayPath(filePath), spans)
+	if Opts.Long {
+		line = resultTable.Line(filePath, DisplayPath(filePath), spans)
+	}
+	fmt.Printf("%s %s\n", Colors.Cyan(fmt.Sprintf("  [%d]", count)), line)
+}
+
+// resultLine describes a result with its git status, icon, color and size,
+// showing it as label (the full path in a list, the name in a tree) with
+// the matched spans highlighted
+func resultLine(filePath, label string, spans []Span) string {
+	gitStatus := getGitStatus(filePath)
+
 	info, err := os.Lstat(filePath)
 	if err != nil {
-		fmt.Printf("  [%d] %s\n", count, filePath)
-		return
+		return gitStatus + hyperlink(filePath, paintLabel(filePath, label, spans, noColor))
 	}
 
 	// Get file info string (size if applicable)
 	fileInfo := getFileInfo(filePath, info)
+	icon := resultIcon(filePath, info)
 
 	// Determine file type and display accordingly
 	if info.IsDir() {
 		// Directory
-		fmt.Printf("%s %s%s\n",
-			Colors.Cyan(fmt.Sprintf("  [%d]", count)),
-			Colors.Blue(fmt.Sprintf("📁 %s%c", filePath, filepath.Separator)),
-			fileInfo)
+		paint := resultColor(filePath, info, Colors.Directory)
+		return gitStatus + paint("%s", icon) + hyperlink(filePath, paintLabel(filePath, label, spans, paint)) + paint("%c", filepath.Separator) + fileInfo
 	} else if info.Mode()&os.ModeSymlink != 0 {
 		// Symlink
-		fmt.Printf("%s %s%s\n",
-			Colors.Cyan(fmt.Sprintf("  [%d]", count)),
-			Colors.Magenta(fmt.Sprintf("🔗 %s", filePath)),
-			fileInfo)
+		paint := resultColor(filePath, info, Colors.Magenta)
+		return gitStatus + paint("%s", icon) + hyperlink(filePath, paintLabel(filePath, label, spans, paint)) + getLinkTarget(filePath) + fileInfo
 	} else if platform.IsExecutable(filePath) {
 		// Executable
-		fmt.Printf("%s %s%s\n",
-			Colors.Cyan(fmt.Sprintf("  [%d]", count)),
-			Colors.Green(fmt.Sprintf("⚡ %s", filePath)),
-			fileInfo)
-	} else {
-		// Regular file
-		fmt.Printf("%s 📄 %s%s\n",
-			Colors.Cyan(fmt.Sprintf("  [%d]", count)),
-			filePath,
-			fileInfo)
+		paint := resultColor(filePath, info, Colors.Green)
+		return gitStatus + paint("%s", icon) + hyperlink(filePath, paintLabel(filePath, label, spans, paint)) + fileInfo
+	}
+	// Regular file, with an icon for its name or detected kind
+	return gitStatus + icon + hyperlink(filePath, paintLabel(filePath, label, spans, resultColor(filePath, info, noColor))) + fileInfo
+}
+
+// resultIcon returns the --icons icon for a result followed by a space, or ""
+func resultIcon(path string, info os.FileInfo) string {
+	icon := icons.For(icons.Set(Opts.Icons), path, info)
+	if icon == "" {
+		return ""
+	}
+	return icon + " "
+}
+
+// getLinkTarget returns " → target" for a symlink, marked when the target is missing
+func getLinkTarget(path string) string {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Sprintf(" %s %s", Colors.Dim("→"), Colors.Red(target+" (broken)"))
+	}
+	return Colors.Dim(" → " + target)
+}
+
+// getGitStatus returns the git status column (M, A, ??) if GitStatus is enabled
+func getGitStatus(path string) string {
+	if !Opts.GitStatus {
+		return ""
+	}
+
+	code := git.Status(path)
+	column := fmt.Sprintf("%-2s ", code)
+	switch code {
+	case "":
+		return column
+	case "A":
+		return Colors.Green(column)
+	case "??":
+		return Colors.Red(column)
+	case "!!":
+		return Colors.Dim(column)
+	default:
+		return Colors.Yellow(column)
 	}
 }
 
 // getFileInfo returns formatted file size info if ShowSize is enabled
+// With --long the size has its own column
 func getFileInfo(path string, info os.FileInfo) string {
-	if !Opts.ShowSize || info.IsDir() {
+	if Opts.Long {
+		return ""
+	}
+	if info.IsDir() {
+		if !Opts.DirSizes {
+			return ""
+		}
+		return Colors.Dim(fmt.Sprintf(" (%s)", FormatSize(usage.DirSize(path))))
+	}
+	if !Opts.ShowSize {
 		return ""
 	}
 	return Colors.Dim(fmt.Sprintf(" (%s)", FormatSize(info.Size())))
@@ -158,34 +330,71 @@ func FormatSize(bytes int64) string {
 
 // showSearchInfo displays search parameters
 func ShowSearchInfo(searchPath, pattern string, usingFd bool) {
+	if Scripted() {
+		startFormattedOutput()
+		return
+	}
 	fmt.Println(Colors.Bold("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
 	fmt.Printf("%s %s\n", Colors.Blue("Searching in:"), Colors.Cyan(searchPath))
 	fmt.Printf("%s %s\n", Colors.Blue("Pattern:"), Colors.Yellow(pattern))
 
+	if root, ok := git.Detect(searchPath); ok {
+		fmt.Printf("%s %s\n", Colors.Blue("Repository:"), Colors.Cyan(root))
+	}
+	if Opts.Git != "" {
+		fmt.Printf("%s %s\n", Colors.Blue("Git filter:"), Colors.Yellow(Opts.Git))
+	}
+	if Opts.Kind != "" {
+		fmt.Printf("%s %s\n", Colors.Blue("Kind:"), Colors.Yellow(Opts.Kind))
+	}
+
 	if usingFd {
 		fmt.Printf("%s %s\n", Colors.Blue("Method:"), Colors.Green("fd (parallel search)"))
+	} else if Opts.BFS {
+		fmt.Printf("%s %s\n", Colors.Blue("Method:"), Colors.Yellow("walk (breadth-first - shallow matches first)"))
 	} else {
 		fmt.Printf("%s %s\n", Colors.Blue("Method:"), Colors.Yellow("walk (sequential - install 'fd' for faster search)"))
 	}
+	if Opts.Timeout > 0 {
+		fmt.Printf("%s %s\n", Colors.Blue("Timeout:"), Colors.Cyan(Opts.Timeout.String()))
+	}
 	fmt.Println(Colors.Bold("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
 	fmt.Println()
 }
 
 // showSummary displays search results summary
 func ShowSummary(count int, elapsed float64) {
-	ShowSummaryWithStatus(count, elapsed, false)
+	ShowSummaryWithStatus(count, elapsed, StatusComplete)
 }
 
-// ShowSummaryWithStatus displays search results summary with optional stopped status
-func ShowSummaryWithStatus(count int, elapsed float64, stopped bool) {
+// ShowSummaryWithStatus displays search results summary with how the search finished
+func ShowSummaryWithStatus(count int, elapsed float64, status SearchStatus) {
+	if Scripted() {
+		return
+	}
 	fmt.Println()
 	fmt.Println(Colors.Bold("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
 
-	if stopped {
+	if status == StatusStopped {
 		fmt.Printf("%s %s in %s\n",
 			Colors.Yellow("Search stopped."),
-			Colors.Green(fmt.Sprintf("Found %d match(es)", count)),
+			Colors.Summary(fmt.Sprintf("Found %d match(es)", count)),
+			Colors.Cyan(fmt.Sprintf("%.2fs", elapsed)))
+	} else if status == StatusTimedOut {
+		fmt.Printf("%s %s in %s\n",
+			Colors.Yellow(fmt.Sprintf("Search timed out after %s.", Opts.Timeout)),
+			Colors.Summary(fmt.Sprintf("Found %d match(es)", count)),
+			Colors.Cyan(fmt.Sprintf("%.2fs", elapsed)))
+		fmt.Println(Colors.Dim("(Results are partial)"))
+	} else if status == StatusLimitReached {
+		fmt.Printf("%s %s in %s\n",
+			Colors.Yellow("Limit reached."),
+			Colors.Summary(fmt.Sprintf("Found first %d match(es)", count)),
 			Colors.Cyan(fmt.Sprintf("%.2fs", elapsed)))
+
+		if Opts.MaxDisplay > 0 && count > Opts.MaxDisplay {
+			fmt.Printf("%s\n", Colors.Yellow(fmt.Sprintf("(Displayed first %d of %d)", Opts.MaxDisplay, count)))
+		}
 	} else if count == 0 {
 		fmt.Printf("%s for pattern: %s\n", Colors.Yellow("No matches found"), Colors.Cyan(Opts.Pattern))
 		fmt.Println()
@@ -194,7 +403,7 @@ func ShowSummaryWithStatus(count int, elapsed float64, stopped bool) {
 		fmt.Printf("  - Use %s for case-insensitive search\n", Colors.Cyan("-i"))
 	} else {
 		fmt.Printf("%s in %s\n",
-			Colors.Green(Colors.Bold(fmt.Sprintf("Found %d match(es)", count))),
+			Colors.Summary(Colors.Bold(fmt.Sprintf("Found %d match(es)", count))),
 			Colors.Cyan(fmt.Sprintf("%.2fs", elapsed)))
 
 		if Opts.MaxDisplay > 0 && count > Opts.MaxDisplay {
@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// ErrorKind classifies why a path could not be read
+type ErrorKind int
+
+const (
+	ErrPermission ErrorKind = iota // permission denied
+	ErrNotExist                    // removed while searching or dangling
+	ErrIO                          // any other I/O error
+)
+
+// String returns the label shown for the error kind
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrPermission:
+		return "permission denied"
+	case ErrNotExist:
+		return "not found"
+	default:
+		return "i/o error"
+	}
+}
+
+// ClassifyError maps an error to the kind shown in the summary
+func ClassifyError(err error) ErrorKind {
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		return ErrPermission
+	case errors.Is(err, fs.ErrNotExist):
+		return ErrNotExist
+	default:
+		return ErrIO
+	}
+}
+
+// PathError records a path that could not be read during a search
+type PathError struct {
+	Path string
+	Kind ErrorKind
+	Dir  bool // true if the directory's contents were skipped
+	Err  error
+}
+
+// ShowErrors displays how many paths could not be read
+// With list set (--show-errors), every path is printed with its error kind
+func ShowErrors(errs []PathError, list bool) {
+	if len(errs) == 0 {
+		return
+	}
+
+	// Keep scripted output on stdout parseable
+	out := os.Stdout
+	if Scripted() {
+		out = os.Stderr
+	}
+
+	dirs := 0
+	for _, e := range errs {
+		if e.Dir {
+			dirs++
+		}
+	}
+	others := len(errs) - dirs
+
+	if dirs > 0 {
+		fmt.Fprintf(out, "%s %s\n", Colors.Yellow("⚠"), Colors.Yellow(plural(dirs, "directory", "directories")+" could not be read"))
+	}
+	if others > 0 {
+		fmt.Fprintf(out, "%s %s\n", Colors.Yellow("⚠"), Colors.Yellow(plural(others, "path", "paths")+" could not be read"))
+	}
+
+	if !list {
+		fmt.Fprintln(out, Colors.Dim(fmt.Sprintf("(use %s to list them)", "--show-errors")))
+		return
+	}
+
+	fmt.Fprintln(out)
+	for _, e := range errs {
+		fmt.Fprintf(out, "  %s %s\n", Colors.Error(fmt.Sprintf("%-17s", e.Kind.String())), e.Path)
+	}
+}
+
+// plural formats a count with the singular or plural noun
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, pluralForm)
+}
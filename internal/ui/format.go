@@ -0,0 +1,243 @@
+package ui
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ReggieAlbiosA/fcf/internal/platform"
+	"github.com/ReggieAlbiosA/fcf/internal/usage"
+)
+
+// FormatFields are the fields a --format template can use
+var FormatFields = []string{"path", "name", "dir", "ext", "size", "mode", "owner", "mtime", "depth", "type", "index"}
+
+// Columns printed by --csv and --tsv
+var tableFields = []string{"path", "name", "type", "size", "mode", "owner", "mtime"}
+
+// formatPart is literal text followed by an optional {field:arg}
+type formatPart struct {
+	text  string
+	field string
+	arg   string
+}
+
+// Format renders results as text, from a --format template or as CSV/TSV rows
+type Format struct {
+	parts []formatPart
+	comma rune // column separator for --csv and --tsv, 0 for a template
+}
+
+// outputFormat is set by SetFormat; nil prints results as usual
+var outputFormat *Format
+
+// formatStarted is set once the CSV/TSV header row is printed
+var formatStarted bool
+
+// SetFormat parses the --format template, or the --csv/--tsv preset
+func SetFormat(template string, csvRows, tsvRows bool) error {
+	switch {
+	case csvRows:
+		outputFormat = tableFormat(',')
+	case tsvRows:
+		outputFormat = tableFormat('\t')
+	case template != "":
+		f, err := ParseFormat(template)
+		if err != nil {
+			return err
+		}
+		outputFormat = f
+	}
+	return nil
+}
+
+// tableFormat returns the --csv or --tsv preset
+func tableFormat(comma rune) *Format {
+	f := &Format{comma: comma}
+	for _, field := range tableFields {
+		f.parts = append(f.parts, formatPart{field: field})
+	}
+	return f
+}
+
+// ParseFormat parses a template such as '{path}\t{size:h}\t{mtime:2006-01-02}'
+// Fields are written {name} or {name:arg}; {{ and }} are literal braces, and
+// \t, \n, \0 and \\ are escapes
+func ParseFormat(template string) (*Format, error) {
+	f := &Format{}
+	var text strings.Builder
+
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		switch {
+		case c == '\\' && i+1 < len(template):
+			i++
+			switch template[i] {
+			case 't':
+				text.WriteByte('\t')
+			case 'n':
+				text.WriteByte('\n')
+			case '0':
+				text.WriteByte(0)
+			case '\\':
+				text.WriteByte('\\')
+			default:
+				text.WriteByte('\\')
+				text.WriteByte(template[i])
+			}
+		case c == '{' && strings.HasPrefix(template[i:], "{{"), c == '}' && strings.HasPrefix(template[i:], "}}"):
+			text.WriteByte(c)
+			i++
+		case c == '{':
+			end := strings.IndexByte(template[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed '{' in format")
+			}
+			field, arg, _ := strings.Cut(template[i+1:i+end], ":")
+			if !isFormatField(field) {
+				return nil, fmt.Errorf("unknown field {%s} in format (use %s)", field, strings.Join(FormatFields, ", "))
+			}
+			if field == "size" && arg != "" && arg != "h" {
+				return nil, fmt.Errorf("unknown size style '%s' in format (use {size} or {size:h})", arg)
+			}
+			f.parts = append(f.parts, formatPart{text: text.String(), field: field, arg: arg})
+			text.Reset()
+			i += end
+		default:
+			text.WriteByte(c)
+		}
+	}
+
+	if text.Len() > 0 {
+		f.parts = append(f.parts, formatPart{text: text.String()})
+	}
+	return f, nil
+}
+
+// isFormatField reports whether name is a known template field
+func isFormatField(name string) bool {
+	for _, f := range FormatFields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// startFormattedOutput prints the CSV/TSV header row once the search
+// starts, so that a search with no matches still prints it
+func startFormattedOutput() {
+	f := outputFormat
+	if f == nil || f.comma == 0 || formatStarted {
+		return
+	}
+	w := csv.NewWriter(os.Stdout)
+	w.Comma = f.comma
+	w.Write(tableFields)
+	w.Flush()
+	formatStarted = true
+}
+
+// showFormattedResult prints one result with the output format
+func showFormattedResult(path string, count int) {
+	f := outputFormat
+	info, _ := os.Lstat(path)
+
+	if f.comma == 0 {
+		var b strings.Builder
+		for _, p := range f.parts {
+			b.WriteString(p.text)
+			if p.field != "" {
+				b.WriteString(formatField(p, path, info, count))
+			}
+		}
+		fmt.Println(b.String())
+		return
+	}
+
+	w := csv.NewWriter(os.Stdout)
+	w.Comma = f.comma
+	row := make([]string, len(f.parts))
+	for i, p := range f.parts {
+		row[i] = formatField(p, path, info, count)
+	}
+	w.Write(row)
+	w.Flush()
+}
+
+// formatField returns the value of one field for path; info is nil when
+// the path could not be read, which leaves its file details empty
+func formatField(p formatPart, path string, info os.FileInfo, count int) string {
+	switch p.field {
+	case "path":
+		return relativePath(path)
+	case "name":
+		return filepath.Base(path)
+	case "dir":
+		return filepath.Dir(relativePath(path))
+	case "ext":
+		return strings.TrimPrefix(filepath.Ext(path), ".")
+	case "index":
+		return strconv.Itoa(count)
+	case "depth":
+		return strconv.Itoa(pathDepth(path))
+	}
+
+	if info == nil {
+		return ""
+	}
+	switch p.field {
+	case "size":
+		size := info.Size()
+		if info.IsDir() {
+			// Like --json: a directory's own size means little, so only recursive sizes are shown
+			size = 0
+			if Opts.DirSizes {
+				size = usage.DirSize(path)
+			}
+		}
+		if p.arg == "h" {
+			return FormatSize(size)
+		}
+		return strconv.FormatInt(size, 10)
+	case "mode":
+		return info.Mode().String()
+	case "owner":
+		return platform.Owner(path, info)
+	case "mtime":
+		layout := p.arg
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return info.ModTime().Format(layout)
+	case "type":
+		return typeName(info)
+	}
+	return ""
+}
+
+// pathDepth returns how many levels below the search root path is
+func pathDepth(path string) int {
+	rel, err := filepath.Rel(searchRoot, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// typeName names the kind of entry: file, directory, symlink or other
+func typeName(info os.FileInfo) string {
+	switch {
+	case info.IsDir():
+		return "directory"
+	case info.Mode()&os.ModeSymlink != 0:
+		return "symlink"
+	case info.Mode().IsRegular():
+		return "file"
+	}
+	return "other"
+}
@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		template string
+		want     []formatPart
+	}{
+		{"", nil},
+		{"plain", []formatPart{{text: "plain"}}},
+		{"{path}", []formatPart{{field: "path"}}},
+		{"{path}\\t{size:h}\\t{mtime:2006-01-02}", []formatPart{
+			{field: "path"},
+			{text: "\t", field: "size", arg: "h"},
+			{text: "\t", field: "mtime", arg: "2006-01-02"},
+		}},
+		{"{mtime:15:04:05}", []formatPart{{field: "mtime", arg: "15:04:05"}}},
+		{"[{index}] {name}!", []formatPart{{text: "[", field: "index"}, {text: "] ", field: "name"}, {text: "!"}}},
+		{"{{path}}", []formatPart{{text: "{path}"}}},
+		{"{{{name}}}", []formatPart{{text: "{", field: "name"}, {text: "}"}}},
+		{"a}b", []formatPart{{text: "a}b"}}},
+		{`\t\n\0\\`, []formatPart{{text: "\t\n\x00\\"}}},
+		{`C:\dir`, []formatPart{{text: `C:\dir`}}},
+		{`end\`, []formatPart{{text: `end\`}}},
+		{"{size}", []formatPart{{field: "size"}}},
+		{"{size:h}", []formatPart{{field: "size", arg: "h"}}},
+		{"日本 {ext}", []formatPart{{text: "日本 ", field: "ext"}}},
+	}
+	for _, tt := range tests {
+		f, err := ParseFormat(tt.template)
+		if err != nil {
+			t.Errorf("ParseFormat(%q): %v", tt.template, err)
+			continue
+		}
+		if !reflect.DeepEqual(f.parts, tt.want) {
+			t.Errorf("ParseFormat(%q) = %+v, want %+v", tt.template, f.parts, tt.want)
+		}
+	}
+}
+
+func TestParseFormatFields(t *testing.T) {
+	for _, field := range FormatFields {
+		f, err := ParseFormat("{" + field + "}")
+		if err != nil {
+			t.Errorf("ParseFormat({%s}): %v", field, err)
+			continue
+		}
+		if len(f.parts) != 1 || f.parts[0].field != field {
+			t.Errorf("ParseFormat({%s}) = %+v", field, f.parts)
+		}
+	}
+}
+
+func TestParseFormatErrors(t *testing.T) {
+	tests := []struct {
+		template string
+		err      string
+	}{
+		{"{path", "unclosed '{'"},
+		{"{path}{", "unclosed '{'"},
+		{"{}", "unknown field {}"},
+		{"{Path}", "unknown field {Path}"},
+		{"{bogus:x}", "unknown field {bogus}"},
+		{"{ path }", "unknown field { path }"},
+		{"{size:k}", "unknown size style 'k'"},
+		{"{size:}x{size:hh}", "unknown size style 'hh'"},
+	}
+	for _, tt := range tests {
+		f, err := ParseFormat(tt.template)
+		if err == nil {
+			t.Errorf("ParseFormat(%q) = %+v, want an error", tt.template, f.parts)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ParseFormat(%q) error %q, want it to mention %q", tt.template, err, tt.err)
+		}
+	}
+}
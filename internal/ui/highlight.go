@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// Span is a byte range of a result's path that the search matched
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"` // exclusive
+}
+
+// paintLabel colors label, the way path is shown, with paint, and the parts
+// of it covered by spans with Colors.Match on top; spans are offsets into
+// path, and label must end like path (a shortened or relative path, or a name)
+// Without colors Match adds nothing, so the label reads as plain text
+func paintLabel(path, label string, spans []Span, paint func(format string, a ...interface{}) string) string {
+	if len(spans) == 0 {
+		return paint("%s", label)
+	}
+
+	// Only the common tail of path and label can be mapped
+	common := 0
+	for common < len(path) && common < len(label) && path[len(path)-1-common] == label[len(label)-1-common] {
+		common++
+	}
+	shift := len(label) - len(path)
+	first := len(path) - common
+	for first < len(path) && !utf8.RuneStart(path[first]) {
+		first++
+	}
+
+	marked := make([]bool, len(label))
+	for _, s := range spans {
+		for i := max(s.Start, first); i < s.End && i < len(path); i++ {
+			marked[i+shift] = true
+		}
+	}
+
+	var out string
+	for start := 0; start < len(label); {
+		end := start + 1
+		for end < len(label) && marked[end] == marked[start] {
+			end++
+		}
+		if marked[start] {
+			out += paint("%s", Colors.Match("%s", label[start:end]))
+		} else {
+			out += paint("%s", label[start:end])
+		}
+		start = end
+	}
+	return out
+}
+
+// mergeSpans sorts spans and joins the ones that overlap or touch
+func mergeSpans(spans []Span) []Span {
+	if len(spans) == 0 {
+		return nil
+	}
+	sorted := append([]Span(nil), spans...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	merged := sorted[:1]
+	for _, s := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if s.Start <= last.End {
+			last.End = max(last.End, s.End)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ReggieAlbiosA/fcf/internal/git"
+	"github.com/ReggieAlbiosA/fcf/internal/usage"
+)
+
+// JSONResult is one result in --json and --ndjson output
+type JSONResult struct {
+	Path   string     `json:"path"`
+	Type   string     `json:"type"` // file, directory, symlink or other
+	Size   int64      `json:"size"`
+	Mode   string     `json:"mode"`
+	MTime  string     `json:"mtime"`
+	Target string     `json:"target,omitempty"` // symlink target
+	Broken bool       `json:"broken,omitempty"` // symlink target is missing
+	Git    string     `json:"git,omitempty"`    // with --git-status
+	Match  *JSONMatch `json:"match"`
+}
+
+// JSONMatch describes what the result was matched on
+type JSONMatch struct {
+	Pattern string `json:"pattern"`
+	Name    string `json:"name"`
+	Spans   []Span `json:"spans"` // byte ranges of path that matched
+}
+
+// JSONSummary ends the --json document
+type JSONSummary struct {
+	Count   int     `json:"count"`
+	Elapsed float64 `json:"elapsed"` // seconds
+	Status  string  `json:"status"`  // complete, stopped, limit_reached or timed_out
+	Error   string  `json:"error,omitempty"`
+}
+
+// JSONError is a path that could not be read, in --json output
+type JSONError struct {
+	Path  string `json:"path"`
+	Kind  string `json:"kind"`
+	Error string `json:"error"`
+}
+
+// jsonStarted is set once the opening of the --json document is printed
+var jsonStarted bool
+
+// NewJSONResult describes path for JSON output, with the spans it matched
+func NewJSONResult(path string, spans []Span) JSONResult {
+	spans = mergeSpans(spans)
+	if spans == nil {
+		spans = []Span{}
+	}
+	r := JSONResult{
+		Path:  path,
+		Type:  "other",
+		Match: &JSONMatch{Pattern: Opts.Pattern, Name: filepath.Base(path), Spans: spans},
+	}
+	if Opts.GitStatus {
+		r.Git = git.Status(path)
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return r
+	}
+	r.Size = info.Size()
+	r.Mode = info.Mode().String()
+	r.MTime = info.ModTime().Format(time.RFC3339)
+
+	r.Type = typeName(info)
+	switch r.Type {
+	case "directory":
+		r.Size = 0
+		if Opts.DirSizes {
+			r.Size = usage.DirSize(path)
+		}
+	case "symlink":
+		r.Target, _ = os.Readlink(path)
+		if _, err := os.Stat(path); err != nil {
+			r.Broken = true
+		}
+	}
+	return r
+}
+
+// showJSONResult prints one result as an NDJSON line or as the next element
+// of the --json document's results array
+func showJSONResult(path string, count int, spans []Span) {
+	data := encodeJSON(NewJSONResult(path, spans))
+	if Opts.NDJSON {
+		fmt.Println(data)
+		return
+	}
+
+	if !jsonStarted {
+		fmt.Println(`{"results":[`)
+		jsonStarted = true
+	}
+	if count > 1 {
+		fmt.Println(",")
+	}
+	fmt.Print("  " + data)
+}
+
+// ShowJSONSummary closes the --json document with the summary and errors
+// searchErr is the error that ended the search early, if any
+func ShowJSONSummary(count int, elapsed float64, status SearchStatus, errs []PathError, searchErr error) {
+	if !Opts.JSON {
+		return
+	}
+
+	if !jsonStarted {
+		fmt.Print(`{"results":[`)
+	}
+	fmt.Println()
+	jsonStarted = false
+
+	summary := JSONSummary{Count: count, Elapsed: math.Round(elapsed*1000) / 1000, Status: status.String()}
+	if searchErr != nil {
+		summary.Error = searchErr.Error()
+	}
+	jsonErrs := make([]JSONError, len(errs))
+	for i, e := range errs {
+		jsonErrs[i] = JSONError{Path: e.Path, Kind: e.Kind.String(), Error: e.Err.Error()}
+	}
+
+	fmt.Printf("],\n\"summary\":%s,\n\"errors\":%s}\n", encodeJSON(summary), encodeJSON(jsonErrs))
+}
+
+// encodeJSON encodes v on one line without escaping <, > and &
+func encodeJSON(v any) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "null"
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ReggieAlbiosA/fcf/internal/platform"
+	"github.com/ReggieAlbiosA/fcf/internal/usage"
+)
+
+// LongTable formats ls -l style columns: mode, links, owner, group, size
+// and mtime. Widths only grow, so rows printed as results stream in stay
+// aligned with the ones before them as long as no value is wider than the
+// provisional widths; Measure fixes the widths when every row is known
+// The zero value has no widths, for tables measured before they are drawn
+type LongTable struct {
+	mode, links, owner, group, size int
+}
+
+// NewLongTable returns a table with provisional widths that fit most rows
+func NewLongTable() *LongTable {
+	return &LongTable{mode: 10, links: 2, owner: 8, group: 8, size: 6}
+}
+
+// longColumns holds the column values of one row
+type longColumns struct {
+	mode, links, owner, group, size, mtime string
+}
+
+// columns returns the column values for path
+func columns(path string, info os.FileInfo) longColumns {
+	size := info.Size()
+	if info.IsDir() && Opts.DirSizes {
+		size = usage.DirSize(path)
+	}
+	return longColumns{
+		mode:  lsMode(info.Mode()),
+		links: strconv.FormatUint(platform.Links(path, info), 10),
+		owner: platform.Owner(path, info),
+		group: platform.Group(path, info),
+		size:  FormatSize(size),
+		mtime: longTime(info.ModTime()),
+	}
+}
+
+// Measure widens the columns to fit path
+func (t *LongTable) Measure(path string, info os.FileInfo) {
+	t.fit(columns(path, info))
+}
+
+// fit widens the columns to fit c
+func (t *LongTable) fit(c longColumns) {
+	t.mode = max(t.mode, len(c.mode))
+	t.links = max(t.links, len(c.links))
+	t.owner = max(t.owner, len(c.owner))
+	t.group = max(t.group, len(c.group))
+	t.size = max(t.size, len(c.size))
+}
+
+// Row returns the columns for path, padded and followed by a space
+func (t *LongTable) Row(path string, info os.FileInfo) string {
+	c := columns(path, info)
+	t.fit(c)
+	return fmt.Sprintf("%s %*s %-*s %-*s %*s %s ",
+		Colors.Dim(fmt.Sprintf("%-*s", t.mode, c.mode)),
+		t.links, c.links,
+		t.owner, c.owner,
+		t.group, c.group,
+		t.size, c.size,
+		Colors.Dim(c.mtime))
+}
+
+// Line returns the columns for path followed by the result as shown in a
+// list, labelled with label and with spans highlighted
+func (t *LongTable) Line(path, label string, spans []Span) string {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return t.Blank() + resultLine(path, label, spans)
+	}
+	return t.Row(path, info) + resultLine(path, label, spans)
+}
+
+// Blank returns spaces as wide as a row, for lines without a file
+func (t *LongTable) Blank() string {
+	// mtime is always 12 wide; six spaces separate the columns
+	return strings.Repeat(" ", t.mode+t.links+t.owner+t.group+t.size+12+6)
+}
+
+// lsMode formats a mode as ls does, e.g. lrwxrwxrwx or drwxrwxrwt
+// (os.FileMode.String uses L for links and puts special bits in front)
+func lsMode(m os.FileMode) string {
+	b := []byte("----------")
+	switch {
+	case m&os.ModeDir != 0:
+		b[0] = 'd'
+	case m&os.ModeSymlink != 0:
+		b[0] = 'l'
+	case m&os.ModeNamedPipe != 0:
+		b[0] = 'p'
+	case m&os.ModeSocket != 0:
+		b[0] = 's'
+	case m&os.ModeCharDevice != 0:
+		b[0] = 'c'
+	case m&os.ModeDevice != 0:
+		b[0] = 'b'
+	}
+
+	const rwx = "rwxrwxrwx"
+	for i := 0; i < 9; i++ {
+		if m&(1<<uint(8-i)) != 0 {
+			b[i+1] = rwx[i]
+		}
+	}
+
+	// setuid, setgid and sticky replace the execute bits
+	special := func(i int, set bool, on, off byte) {
+		if !set {
+			return
+		}
+		if b[i] == 'x' {
+			b[i] = on
+		} else {
+			b[i] = off
+		}
+	}
+	special(3, m&os.ModeSetuid != 0, 's', 'S')
+	special(6, m&os.ModeSetgid != 0, 's', 'S')
+	special(9, m&os.ModeSticky != 0, 't', 'T')
+	return string(b)
+}
+
+// longTime formats a time like ls: the time of day for the last six
+// months, the year for anything older or in the future
+func longTime(t time.Time) string {
+	now := time.Now()
+	if t.After(now.AddDate(0, -6, 0)) && !t.After(now) {
+		return t.Format("Jan _2 15:04")
+	}
+	return t.Format("Jan _2  2006")
+}
+
+// resultTable aligns --long rows across a search
+var resultTable = NewLongTable()
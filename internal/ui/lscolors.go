@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/ReggieAlbiosA/fcf/internal/platform"
+)
+
+// lsColors holds the styles parsed from LS_COLORS
+type lsColors struct {
+	types    map[string][]color.Attribute // di, ln, ex, fi, or, pi, so, bd, cd
+	suffixes []lsSuffix                   // *.ext entries, in order
+}
+
+// lsSuffix is a "*suffix=codes" entry of LS_COLORS
+type lsSuffix struct {
+	suffix string
+	attrs  []color.Attribute
+}
+
+// resultColors is set by InitColors when LS_COLORS is set and colors are on
+var resultColors *lsColors
+
+// parseLSColors parses LS_COLORS, e.g. "di=01;34:ln=01;36:*.tar=01;31"
+// Entries that cannot be parsed are skipped, as ls does
+func parseLSColors(value string) *lsColors {
+	if value == "" {
+		return nil
+	}
+	ls := &lsColors{types: map[string][]color.Attribute{}}
+	for _, entry := range strings.Split(value, ":") {
+		key, codes, ok := strings.Cut(entry, "=")
+		if !ok || key == "" {
+			continue
+		}
+		attrs, ok := parseSGR(codes)
+		if !ok {
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(key, "*"); ok {
+			ls.suffixes = append(ls.suffixes, lsSuffix{suffix: suffix, attrs: attrs})
+		} else {
+			ls.types[key] = attrs
+		}
+	}
+	return ls
+}
+
+// style returns the LS_COLORS attributes for path, or false if none apply
+func (ls *lsColors) style(path string, info os.FileInfo) ([]color.Attribute, bool) {
+	mode := info.Mode()
+	key := "fi"
+	switch {
+	case mode.IsDir():
+		key = "di"
+	case mode&os.ModeSymlink != 0:
+		key = "ln"
+		if _, err := os.Stat(path); err != nil {
+			key = "or"
+			if _, ok := ls.types[key]; !ok {
+				key = "ln"
+			}
+		}
+	case mode&os.ModeNamedPipe != 0:
+		key = "pi"
+	case mode&os.ModeSocket != 0:
+		key = "so"
+	case mode&os.ModeCharDevice != 0:
+		key = "cd"
+	case mode&os.ModeDevice != 0:
+		key = "bd"
+	case platform.IsExecutable(path):
+		key = "ex"
+	}
+
+	// Extensions only restyle regular files; ls gives executables their own color first
+	if key == "fi" {
+		name := filepath.Base(path)
+		for _, s := range ls.suffixes {
+			if strings.HasSuffix(name, s.suffix) || strings.HasSuffix(strings.ToLower(name), strings.ToLower(s.suffix)) {
+				return s.attrs, true
+			}
+		}
+	}
+	attrs, ok := ls.types[key]
+	return attrs, ok
+}
+
+// resultColor returns the color function for a result: its LS_COLORS
+// style when there is one, and fallback otherwise
+func resultColor(path string, info os.FileInfo, fallback func(format string, a ...interface{}) string) func(format string, a ...interface{}) string {
+	if resultColors == nil {
+		return fallback
+	}
+	if attrs, ok := resultColors.style(path, info); ok {
+		return styleFunc(attrs...)
+	}
+	return fallback
+}
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Relative is the --relative flag: off, "root" or "cwd"
+// Given without a value it means "root"
+type Relative string
+
+// String returns the flag value
+func (r *Relative) String() string { return string(*r) }
+
+// Set parses the flag value
+func (r *Relative) Set(value string) error {
+	switch strings.ToLower(value) {
+	case "true", "root":
+		*r = "root"
+	case "cwd":
+		*r = "cwd"
+	case "false":
+		*r = ""
+	default:
+		return fmt.Errorf("use root or cwd")
+	}
+	return nil
+}
+
+// IsBoolFlag lets --relative be given without a value
+func (r *Relative) IsBoolFlag() bool { return true }
+
+// searchRoot is the absolute root of the current search
+var searchRoot string
+
+// SetSearchRoot records the absolute root of the search being shown
+func SetSearchRoot(root string) {
+	searchRoot = root
+}
+
+// relativePath returns path relative to the search root or the working
+// directory, as chosen with --relative, or path itself
+func relativePath(path string) string {
+	base := searchRoot
+	switch Opts.Relative {
+	case "":
+		return path
+	case "cwd":
+		cwd, err := os.Getwd()
+		if err != nil {
+			return path
+		}
+		base = cwd
+	}
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// DisplayPath returns path as shown to people: relative with --relative,
+// and otherwise with the home directory abbreviated to ~
+func DisplayPath(path string) string {
+	if Opts.Relative != "" {
+		return relativePath(path)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" || home == string(filepath.Separator) {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if rest, ok := strings.CutPrefix(path, home+string(filepath.Separator)); ok {
+		return "~" + string(filepath.Separator) + rest
+	}
+	return path
+}
+
+// hostname is the host named in file:// hyperlinks
+var hostname, _ = os.Hostname()
+
+// hyperlink makes text a terminal hyperlink to path (OSC 8) when
+// --hyperlink is set, so that clicking it opens the file
+func hyperlink(path, text string) string {
+	if !Opts.Hyperlink {
+		return text
+	}
+	return fmt.Sprintf("\033]8;;%s\033\\%s\033]8;;\033\\", fileURL(path), text)
+}
+
+// fileURL returns the file://host/path URL for an absolute path, with
+// spaces, #, ? and non-ASCII characters percent-encoded
+func fileURL(path string) string {
+	p := filepath.ToSlash(path)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p // C:/Users -> /C:/Users
+	}
+	u := url.URL{Scheme: "file", Host: hostname, Path: p}
+	return u.String()
+}
@@ -0,0 +1,221 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ReggieAlbiosA/fcf/internal/input"
+	"github.com/ReggieAlbiosA/fcf/internal/store"
+)
+
+// picker is the state of the full-screen result picker
+type picker struct {
+	results *store.Paths
+	matches []int // indexes into results that pass the filter
+	query   []rune
+	typing  bool // keys go to the filter, so j and k are letters
+	cursor  int  // position in matches
+	top     int  // first match shown
+
+	// Rendered rows by result index, so moving the cursor does not stat
+	// and paint every visible result again; cleared when the query changes
+	lines map[int]string
+}
+
+// PickResult lets the user choose one of results full screen: arrows, j/k,
+// Page Up/Down, Home/End to move, typing to filter, Enter to choose and Esc
+// to cancel. It returns the chosen path, or "" when cancelled, and an error
+// when the terminal cannot be taken over
+func PickResult(results *store.Paths) (string, error) {
+	screen, err := input.OpenScreen()
+	if err != nil {
+		return "", err
+	}
+	defer screen.Close()
+
+	p := &picker{results: results}
+	p.filter()
+	for {
+		p.draw()
+		select {
+		case <-screen.Resize:
+		case k := <-screen.Keys:
+			if path, done := p.handle(k); done {
+				return path, nil
+			}
+		}
+	}
+}
+
+// handle applies a key; done is true once a result is chosen or the
+// picker is cancelled
+func (p *picker) handle(k input.Key) (path string, done bool) {
+	switch k.Code {
+	case input.KeyEnter:
+		if len(p.matches) == 0 {
+			return "", false
+		}
+		return p.results.At(p.matches[p.cursor]), true
+	case input.KeyEscape:
+		return "", true
+	case input.KeyUp:
+		p.move(-1)
+	case input.KeyDown:
+		p.move(1)
+	case input.KeyPageUp:
+		p.move(-p.rows())
+	case input.KeyPageDown:
+		p.move(p.rows())
+	case input.KeyHome:
+		p.move(-len(p.matches))
+	case input.KeyEnd:
+		p.move(len(p.matches))
+	case input.KeyBackspace:
+		if len(p.query) > 0 {
+			p.query = p.query[:len(p.query)-1]
+			p.filter()
+		}
+		p.typing = len(p.query) > 0
+	case input.KeyClear:
+		p.query = nil
+		p.typing = false
+		p.filter()
+	case input.KeyRune:
+		switch {
+		case !p.typing && k.Rune == 'j':
+			p.move(1)
+		case !p.typing && k.Rune == 'k':
+			p.move(-1)
+		case !p.typing && k.Rune == '/':
+			// Start a filter, which may then begin with j or k
+			p.typing = true
+		default:
+			p.typing = true
+			p.query = append(p.query, k.Rune)
+			p.filter()
+		}
+	}
+	return "", false
+}
+
+// move moves the cursor by delta matches, within the list
+func (p *picker) move(delta int) {
+	p.cursor = max(0, min(p.cursor+delta, len(p.matches)-1))
+}
+
+// filter keeps the results whose shown path contains every word of the
+// query, ignoring case unless the query has uppercase letters
+func (p *picker) filter() {
+	words := strings.Fields(string(p.query))
+	p.matches = p.matches[:0]
+	for i := 0; i < p.results.Len(); i++ {
+		if len(words) == 0 || pickerSpans(DisplayPath(p.results.At(i)), words) != nil {
+			p.matches = append(p.matches, i)
+		}
+	}
+	p.cursor, p.top = 0, 0
+	p.lines = make(map[int]string)
+}
+
+// pickerSpans returns where each word occurs in label, or nil if one of
+// them does not
+func pickerSpans(label string, words []string) []Span {
+	var spans []Span
+	for _, w := range words {
+		i := indexSmartCase(label, w)
+		if i < 0 {
+			return nil
+		}
+		spans = append(spans, Span{Start: i, End: i + len(w)})
+	}
+	return spans
+}
+
+// indexSmartCase finds word in s, ignoring case unless word has uppercase
+// letters, and returns its byte offset or -1
+func indexSmartCase(s, word string) int {
+	if strings.IndexFunc(word, unicode.IsUpper) >= 0 {
+		return strings.Index(s, word)
+	}
+	for i := 0; i+len(word) <= len(s); {
+		if strings.EqualFold(s[i:i+len(word)], word) {
+			return i
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return -1
+}
+
+// rows returns how many results fit on the screen below the prompt and
+// above the key help
+func (p *picker) rows() int {
+	_, height, err := input.TerminalSize()
+	if err != nil || height < 4 {
+		return 1
+	}
+	return height - 3
+}
+
+// render returns the row of result index, with the query words
+// highlighted
+func (p *picker) render(index int, words []string) string {
+	if l, ok := p.lines[index]; ok {
+		return l
+	}
+	path := p.results.At(index)
+	label := DisplayPath(path)
+
+	// Filter spans are offsets into label; resultLine wants them in path
+	spans := pickerSpans(label, words)
+	for j := range spans {
+		spans[j].Start += len(path) - len(label)
+		spans[j].End += len(path) - len(label)
+	}
+
+	l := resultLine(path, label, spans)
+	p.lines[index] = l
+	return l
+}
+
+// draw redraws the whole screen in one write, so it does not flicker
+func (p *picker) draw() {
+	rows := p.rows()
+	if p.cursor < p.top {
+		p.top = p.cursor
+	}
+	if p.cursor >= p.top+rows {
+		p.top = p.cursor - rows + 1
+	}
+
+	var b strings.Builder
+	b.WriteString("\033[H")
+	line := func(s string) {
+		b.WriteString(s + "\033[K\r\n")
+	}
+
+	count := Colors.Dim(fmt.Sprintf("%d/%d", len(p.matches), p.results.Len()))
+	line(fmt.Sprintf("%s %s %s", Colors.Prompt(">"), string(p.query), count))
+
+	words := strings.Fields(string(p.query))
+	width := len(fmt.Sprint(p.results.Len()))
+	for i := p.top; i < p.top+rows; i++ {
+		if i >= len(p.matches) {
+			line("")
+			continue
+		}
+		index := p.matches[i]
+		marker := "  "
+		if i == p.cursor {
+			marker = Colors.Cyan("❯ ")
+		}
+		line(fmt.Sprintf("%s%s %s", marker, Colors.Cyan(fmt.Sprintf("[%*d]", width, index+1)), p.render(index, words)))
+	}
+
+	b.WriteString(Colors.Dim("↑/↓ j/k move · PgUp/PgDn page · type to filter · Enter open · Esc cancel"))
+	b.WriteString("\033[K\033[J")
+	os.Stdout.WriteString(b.String())
+}
@@ -0,0 +1,197 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mattn/go-isatty"
+
+	"github.com/ReggieAlbiosA/fcf/internal/input"
+)
+
+// progressInterval is how often the status line is redrawn
+const progressInterval = 100 * time.Millisecond
+
+// Progress draws a status line below the streaming results while a search runs
+// All methods are safe to call on a nil *Progress, which draws nothing
+type Progress struct {
+	mu        sync.Mutex
+	start     time.Time
+	dirs      int
+	entries   int
+	current   string
+	countOnly bool // fd reports entries only, not directories
+	drawn     bool
+	done      chan struct{}
+	stopped   chan struct{}
+}
+
+// StartProgress starts redrawing the status line until Stop is called
+// countOnly is used for fd, where only emitted entries can be counted
+// Returns nil when stdout is not a terminal or output is scripted
+func StartProgress(countOnly bool) *Progress {
+	if Scripted() || (!isatty.IsTerminal(os.Stdout.Fd()) && !isatty.IsCygwinTerminal(os.Stdout.Fd())) {
+		return nil
+	}
+
+	p := &Progress{
+		start:     time.Now(),
+		countOnly: countOnly,
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(p.stopped)
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.done:
+				return
+			case <-ticker.C:
+				p.mu.Lock()
+				p.draw()
+				p.mu.Unlock()
+			}
+		}
+	}()
+
+	return p
+}
+
+// AddDir records that a directory is being scanned
+func (p *Progress) AddDir(path string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.dirs++
+	p.current = path
+	p.mu.Unlock()
+}
+
+// AddEntry records that an entry was seen
+func (p *Progress) AddEntry(path string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.entries++
+	if p.countOnly {
+		p.current = filepath.Dir(path)
+	}
+	p.mu.Unlock()
+}
+
+// Print runs show with the status line cleared, then redraws it below the new output
+func (p *Progress) Print(show func()) {
+	if p == nil {
+		show()
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	show()
+	p.draw()
+}
+
+// Stop stops redrawing and removes the status line
+func (p *Progress) Stop() {
+	if p == nil {
+		return
+	}
+	close(p.done)
+	<-p.stopped
+
+	p.mu.Lock()
+	p.clear()
+	p.mu.Unlock()
+}
+
+// clear erases the status line; callers hold p.mu
+func (p *Progress) clear() {
+	if p.drawn {
+		fmt.Print("\r\033[K")
+		p.drawn = false
+	}
+}
+
+// draw renders the status line in place; callers hold p.mu
+func (p *Progress) draw() {
+	elapsed := time.Since(p.start)
+	rate := 0
+	if secs := elapsed.Seconds(); secs > 0 {
+		rate = int(float64(p.entries) / secs)
+	}
+
+	var counts string
+	if p.countOnly {
+		counts = fmt.Sprintf("%s entries", formatCount(p.entries))
+	} else {
+		counts = fmt.Sprintf("%s dirs, %s entries", formatCount(p.dirs), formatCount(p.entries))
+	}
+	status := fmt.Sprintf("%s (%s/s) · %.1fs · ", counts, formatCount(rate), elapsed.Seconds())
+	hint := "  [s] stop"
+
+	// Keep the line on a single row so \r can redraw it, shortening the directory first
+	width := 80
+	if w, _, err := input.TerminalSize(); err == nil && w > 0 {
+		width = w
+	}
+	room := width - 1 - utf8.RuneCountInString(status) - utf8.RuneCountInString(hint)
+	current := truncateLeft(p.current, room)
+	if room < 0 {
+		status = truncateRight(status, width-1)
+		current, hint = "", ""
+	}
+
+	fmt.Print("\r\033[K" + Colors.Dim(status+current) + Colors.Yellow(hint))
+	p.drawn = true
+}
+
+// formatCount formats n with thousands separators
+func formatCount(n int) string {
+	s := fmt.Sprintf("%d", n)
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// truncateLeft shortens s to at most n runes, keeping its end
+func truncateLeft(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n == 1 {
+		return "…"
+	}
+	return "…" + string(runes[len(runes)-n+1:])
+}
+
+// truncateRight shortens s to at most n runes, keeping its start
+func truncateRight(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
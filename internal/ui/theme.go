@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/ReggieAlbiosA/fcf/internal/config"
+)
+
+// ColorModes are the values --color accepts
+var ColorModes = []string{"auto", "always", "never"}
+
+// IsValidColorMode reports whether mode is a --color value
+func IsValidColorMode(mode string) bool {
+	for _, m := range ColorModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// colorEnabled decides whether to print colors
+// --color (or the config file) wins, then NO_COLOR, then CLICOLOR_FORCE,
+// and otherwise colors are used on a terminal
+func colorEnabled() bool {
+	switch Opts.Color {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true
+	}
+	return IsTerminal()
+}
+
+// Style names accepted in the theme file, as SGR codes
+var styleCodes = map[string]color.Attribute{
+	"bold":      color.Bold,
+	"dim":       color.Faint,
+	"italic":    color.Italic,
+	"underline": color.Underline,
+	"reverse":   color.ReverseVideo,
+	"black":     color.FgBlack,
+	"red":       color.FgRed,
+	"green":     color.FgGreen,
+	"yellow":    color.FgYellow,
+	"blue":      color.FgBlue,
+	"magenta":   color.FgMagenta,
+	"cyan":      color.FgCyan,
+	"white":     color.FgWhite,
+}
+
+// parseStyle turns a style such as "bold cyan", "bright-red on-black" or
+// "38;5;208" into SGR attributes
+func parseStyle(style string) ([]color.Attribute, bool) {
+	var attrs []color.Attribute
+	for _, word := range strings.Fields(strings.ToLower(style)) {
+		if word == "none" || word == "plain" {
+			continue
+		}
+		if codes, ok := parseSGR(word); ok {
+			attrs = append(attrs, codes...)
+			continue
+		}
+
+		name, offset := word, color.Attribute(0)
+		if rest, ok := strings.CutPrefix(name, "on-"); ok {
+			name, offset = rest, color.BgBlack-color.FgBlack
+		}
+		if rest, ok := strings.CutPrefix(name, "bright-"); ok {
+			name, offset = rest, offset+color.FgHiBlack-color.FgBlack
+		}
+		code, ok := styleCodes[name]
+		if !ok || (offset != 0 && code < color.FgBlack) {
+			return nil, false
+		}
+		attrs = append(attrs, code+offset)
+	}
+	return attrs, true
+}
+
+// parseSGR parses raw SGR codes such as "01;34"
+func parseSGR(codes string) ([]color.Attribute, bool) {
+	var attrs []color.Attribute
+	for _, part := range strings.Split(codes, ";") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		attrs = append(attrs, color.Attribute(n))
+	}
+	return attrs, true
+}
+
+// styleFunc returns a color function for attrs; no attributes means plain text
+// Colors are forced on because the color package drops them under NO_COLOR,
+// which colorEnabled has already weighed against --color
+func styleFunc(attrs ...color.Attribute) func(format string, a ...interface{}) string {
+	if len(attrs) == 0 {
+		return noColor
+	}
+	c := color.New(attrs...)
+	c.EnableColor()
+	return c.SprintfFunc()
+}
+
+// noColor formats without adding any color
+func noColor(format string, a ...interface{}) string {
+	return fmt.Sprintf(format, a...)
+}
+
+// applyTheme restyles colors from the theme file, one "role = style" per
+// line, where role is match, directory, header, prompt, summary or error
+// Unknown roles and styles are ignored, like unknown config keys
+func applyTheme(c *ColorFuncs, path string) {
+	slots := map[string]*func(format string, a ...interface{}) string{
+		"match":     &c.Match,
+		"directory": &c.Directory,
+		"header":    &c.Header,
+		"prompt":    &c.Prompt,
+		"summary":   &c.Summary,
+		"error":     &c.Error,
+	}
+	for name, style := range config.LoadFile(path) {
+		slot, ok := slots[name]
+		if !ok {
+			continue
+		}
+		if attrs, ok := parseStyle(style); ok {
+			*slot = styleFunc(attrs...)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ReggieAlbiosA/fcf/internal/store"
+)
+
+// treeNode is a path component in the --tree view
+type treeNode struct {
+	name     string
+	path     string
+	index    int // result number, 0 for directories shown only as parents
+	matches  int // matches below this node
+	children []*treeNode
+	byName   map[string]*treeNode
+}
+
+// child returns the child called name, adding it if needed
+func (n *treeNode) child(name string) *treeNode {
+	if c, ok := n.byName[name]; ok {
+		return c
+	}
+	c := &treeNode{name: name}
+	if n.byName == nil {
+		n.byName = map[string]*treeNode{}
+	}
+	n.byName[name] = c
+	n.children = append(n.children, c)
+	return c
+}
+
+// ShowTree prints results as a tree below root, numbered in the order they
+// were found; results must be in depth-first order so the numbers read top
+// to bottom, which --tree ensures by implying --stable
+// spans gives the matched parts of a result, for highlighting
+func ShowTree(root string, results *store.Paths, spans func(path string) []Span) {
+	top := &treeNode{name: root, path: root}
+
+	shown := results.Len()
+	if Opts.MaxDisplay > 0 && shown > Opts.MaxDisplay {
+		shown = Opts.MaxDisplay
+	}
+	for i := 0; i < shown; i++ {
+		path := results.At(i)
+		rel, err := filepath.Rel(root, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+
+		node := top
+		if rel != "." {
+			for _, part := range strings.Split(rel, string(filepath.Separator)) {
+				node.matches++
+				parent := node
+				node = node.child(part)
+				node.path = filepath.Join(parent.path, part)
+			}
+		}
+		node.index = i + 1
+	}
+
+	// Columns are drawn once every row is known, so they can be measured first
+	if Opts.Long {
+		resultTable = &LongTable{}
+		measureTree(top)
+	}
+	fmt.Printf("%s%s%s\n", treeColumns(top), Colors.Bold(DisplayPath(root)), treeCount(top))
+	showTreeChildren(top, "", spans)
+}
+
+// showTreeChildren prints the children of n with box-drawing connectors
+func showTreeChildren(n *treeNode, prefix string, spans func(path string) []Span) {
+	for i, c := range n.children {
+		connector, indent := "├── ", "│   "
+		if i == len(n.children)-1 {
+			connector, indent = "└── ", "    "
+		}
+
+		// Fold chains of directories that only lead to one other directory
+		name := c.name
+		for c.index == 0 && len(c.children) == 1 && c.children[0].index == 0 {
+			c = c.children[0]
+			name = filepath.Join(name, c.name)
+		}
+
+		line := Colors.Directory(name+string(filepath.Separator)) + treeCount(c)
+		if c.index > 0 {
+			line = fmt.Sprintf("%s %s%s", Colors.Cyan(fmt.Sprintf("[%d]", c.index)), resultLine(c.path, name, spans(c.path)), treeCount(c))
+		}
+		fmt.Println(treeColumns(c) + Colors.Dim(prefix+connector) + line)
+		showTreeChildren(c, prefix+indent, spans)
+	}
+}
+
+// measureTree fits the --long columns to n and everything below it
+func measureTree(n *treeNode) {
+	if info, err := os.Lstat(n.path); err == nil {
+		resultTable.Measure(n.path, info)
+	}
+	for _, c := range n.children {
+		measureTree(c)
+	}
+}
+
+// treeColumns returns the --long columns for n, or "" without --long
+func treeColumns(n *treeNode) string {
+	if !Opts.Long {
+		return ""
+	}
+	info, err := os.Lstat(n.path)
+	if err != nil {
+		return resultTable.Blank()
+	}
+	return resultTable.Row(n.path, info)
+}
+
+// treeCount returns the match count shown after a directory, or "" for a leaf
+func treeCount(n *treeNode) string {
+	if n.matches == 0 {
+		return ""
+	}
+	return Colors.Dim(fmt.Sprintf(" (%d)", n.matches))
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package usage
+
+import (
+	"os"
+	"syscall"
+)
+
+// hardlinkID returns the device and inode of a file with more than one
+// link, so every link after the first can be left out of the totals
+func hardlinkID(path string, info os.FileInfo) (fileID, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || uint64(st.Nlink) < 2 {
+		return fileID{}, false
+	}
+	return fileID{dev: uint64(st.Dev), ino: uint64(st.Ino)}, true
+}
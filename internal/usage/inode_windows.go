@@ -0,0 +1,12 @@
+//go:build windows
+
+package usage
+
+import "os"
+
+// hardlinkID is not available on Windows: FileInfo carries neither a link
+// count nor a file index, and opening every file to read them would make a
+// scan many times slower. Hardlinked files are counted once per link
+func hardlinkID(path string, info os.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
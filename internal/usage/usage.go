@@ -0,0 +1,310 @@
+package usage
+
+import (
+	"container/heap"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Entry is a file or directory with its size in bytes
+// A directory's size includes everything below it
+type Entry struct {
+	Path  string
+	Size  int64
+	IsDir bool
+}
+
+// Error records a path that could not be read
+type Error struct {
+	Path string
+	Dir  bool // true if the directory's contents were skipped
+	Err  error
+}
+
+// Options controls a scan
+type Options struct {
+	Top    int               // how many of the largest files and directories to keep
+	Stop   <-chan struct{}   // closed to end the scan early
+	OnDir  func(path string) // called for each directory read, for progress
+	OnFile func(path string) // called for each file counted, for progress
+}
+
+// Report is the result of scanning a tree
+type Report struct {
+	Root     string
+	Total    int64
+	Files    int
+	Dirs     int
+	TopDirs  []Entry // largest first, not including Root
+	TopFiles []Entry // largest first
+	Errors   []Error
+	Stopped  bool
+}
+
+// fileID identifies a file on disk independently of its path
+type fileID struct {
+	dev, ino uint64
+}
+
+// scanner holds the state shared by the goroutines of one scan
+type scanner struct {
+	opts Options
+	sem  chan struct{}
+
+	mu       sync.Mutex
+	dirSizes map[string]int64
+	files    fileHeap
+	seen     map[fileID]bool
+	errors   []Error
+	nFiles   int
+	nDirs    int
+	stopped  bool
+}
+
+// Scan adds up the sizes below root, reading directories in parallel
+// Files with several hardlinks are counted once, except on Windows
+func Scan(root string, opts Options) *Report {
+	s := &scanner{
+		opts:     opts,
+		sem:      make(chan struct{}, runtime.NumCPU()*2),
+		dirSizes: map[string]int64{},
+		seen:     map[fileID]bool{},
+	}
+
+	total := s.scanDir(root)
+	if !s.stopped {
+		storeCache(s.dirSizes)
+	}
+
+	report := &Report{
+		Root:    root,
+		Total:   total,
+		Files:   s.nFiles,
+		Dirs:    s.nDirs,
+		Errors:  s.errors,
+		Stopped: s.stopped,
+	}
+
+	for path, size := range s.dirSizes {
+		if path != root {
+			report.TopDirs = append(report.TopDirs, Entry{Path: path, Size: size, IsDir: true})
+		}
+	}
+	sortBySize(report.TopDirs)
+	if opts.Top > 0 && len(report.TopDirs) > opts.Top {
+		report.TopDirs = report.TopDirs[:opts.Top]
+	}
+
+	report.TopFiles = append(report.TopFiles, s.files...)
+	sortBySize(report.TopFiles)
+	return report
+}
+
+// scanDir returns the total size below dir and records it
+// Subdirectories are scanned in new goroutines while there is a free slot,
+// and inline otherwise, so the number of goroutines stays bounded
+func (s *scanner) scanDir(dir string) int64 {
+	if s.isStopped() {
+		return 0
+	}
+	if s.opts.OnDir != nil {
+		s.opts.OnDir(dir)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		s.addError(Error{Path: dir, Dir: true, Err: err})
+	}
+
+	var total int64
+	var wg sync.WaitGroup
+	subSizes := make([]int64, len(entries))
+
+	for i, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+
+		if entry.IsDir() {
+			select {
+			case s.sem <- struct{}{}:
+				wg.Add(1)
+				go func(i int, path string) {
+					defer wg.Done()
+					defer func() { <-s.sem }()
+					subSizes[i] = s.scanDir(path)
+				}(i, path)
+			default:
+				subSizes[i] = s.scanDir(path)
+			}
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			s.addError(Error{Path: path, Err: err})
+			continue
+		}
+		total += s.addFile(path, info)
+	}
+
+	wg.Wait()
+	for _, size := range subSizes {
+		total += size
+	}
+
+	s.mu.Lock()
+	s.nDirs++
+	s.dirSizes[dir] = total
+	s.mu.Unlock()
+	return total
+}
+
+// addFile counts a file and returns the size it adds to its directory,
+// which is zero for a hardlink already counted elsewhere
+func (s *scanner) addFile(path string, info os.FileInfo) int64 {
+	if s.opts.OnFile != nil {
+		s.opts.OnFile(path)
+	}
+
+	id, linked := hardlinkID(path, info)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if linked {
+		if s.seen[id] {
+			return 0
+		}
+		s.seen[id] = true
+	}
+
+	s.nFiles++
+	size := info.Size()
+	if s.opts.Top > 0 {
+		heap.Push(&s.files, Entry{Path: path, Size: size})
+		if s.files.Len() > s.opts.Top {
+			heap.Pop(&s.files)
+		}
+	}
+	return size
+}
+
+// addError records a path that could not be read
+func (s *scanner) addError(e Error) {
+	s.mu.Lock()
+	s.errors = append(s.errors, e)
+	s.mu.Unlock()
+}
+
+// isStopped reports whether the scan was asked to end
+func (s *scanner) isStopped() bool {
+	if s.opts.Stop == nil {
+		return false
+	}
+	select {
+	case <-s.opts.Stop:
+		s.mu.Lock()
+		s.stopped = true
+		s.mu.Unlock()
+		return true
+	default:
+		return false
+	}
+}
+
+// sortBySize sorts entries largest first, then by path
+func sortBySize(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Size != entries[j].Size {
+			return entries[i].Size > entries[j].Size
+		}
+		return entries[i].Path < entries[j].Path
+	})
+}
+
+// fileHeap is a min-heap of files by size, holding the largest seen so far
+type fileHeap []Entry
+
+func (h fileHeap) Len() int           { return len(h) }
+func (h fileHeap) Less(i, j int) bool { return h[i].Size < h[j].Size }
+func (h fileHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *fileHeap) Push(x any)        { *h = append(*h, x.(Entry)) }
+func (h *fileHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+// Directory sizes from earlier scans, reused by DirSize, and the
+// background scan started by Prescan
+var (
+	cacheMu     sync.Mutex
+	cache       = map[string]int64{}
+	prescanRoot string
+	prescanDone chan struct{} // closed once the background scan has ended
+)
+
+// storeCache remembers the directory sizes of a completed scan
+func storeCache(sizes map[string]int64) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	for path, size := range sizes {
+		cache[path] = size
+	}
+}
+
+// Prescan starts scanning root in the background, so the size of every
+// directory below it comes out of that one scan instead of each directory
+// being walked again when it is shown. The scan ends early if stop is closed
+func Prescan(root string, stop <-chan struct{}) {
+	done := make(chan struct{})
+	cacheMu.Lock()
+	prescanRoot, prescanDone = root, done
+	cacheMu.Unlock()
+
+	go func() {
+		defer close(done)
+		Scan(root, Options{Stop: stop})
+	}()
+}
+
+// DirSize returns the recursive size of dir, waiting for the background
+// scan when dir is below its root and scanning dir on its own otherwise
+// Sizes of the directories below it are kept, so nested results are free
+func DirSize(dir string) int64 {
+	cacheMu.Lock()
+	root, done := prescanRoot, prescanDone
+	cacheMu.Unlock()
+	if done != nil && isBelow(dir, root) {
+		<-done
+	}
+
+	cacheMu.Lock()
+	size, ok := cache[dir]
+	cacheMu.Unlock()
+	if ok {
+		return size
+	}
+	return Scan(dir, Options{}).Total
+}
+
+// isBelow reports whether path is dir or inside it
+func isBelow(path, dir string) bool {
+	if path == dir {
+		return true
+	}
+	prefix := strings.TrimSuffix(dir, string(filepath.Separator)) + string(filepath.Separator)
+	return strings.HasPrefix(path, prefix)
+}
+
+// Reset forgets the cached directory sizes and any background scan
+func Reset() {
+	cacheMu.Lock()
+	cache = map[string]int64{}
+	prescanRoot, prescanDone = "", nil
+	cacheMu.Unlock()
+}